@@ -2,10 +2,13 @@ package circuitbreaker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
+	"runtime/debug"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -18,14 +21,54 @@ const (
 	Closed State = iota
 	Open
 	HalfOpen
+	// ForcedOpen rejects every call until the breaker is released by Reset or
+	// TransitionToHalfOpen.
+	ForcedOpen
+	// ForcedClosed admits every call and records nothing until the breaker is released.
+	ForcedClosed
+	// Disabled admits every call and keeps recording outcomes, but never changes
+	// state on its own until the breaker is released.
+	Disabled
 )
 
+// String returns the lower-case name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	case ForcedOpen:
+		return "forced-open"
+	case ForcedClosed:
+		return "forced-closed"
+	case Disabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("State(%d)", int64(s))
+	}
+}
+
 // CircuitBreaker manages request flow and failure handling.
 type CircuitBreaker interface {
 	Execute(context.Context, func(context.Context) error) (*time.Timer, error)
+	Try(context.Context, func(context.Context) error) error
+	Allow(context.Context) (Ticket, error)
 	ExecuteBlocking(context.Context, func(context.Context) error) error
 	ExecuteHTTPBlocking(context.Context, *http.Client, func() (*http.Request, error)) (*http.Response, error)
 	ExecuteGRPCBlocking(context.Context, func(context.Context) (interface{}, error)) (interface{}, error)
+	ExecuteWithFallback(context.Context, func(context.Context) error, FallbackFunc) error
+	Name() string
+	State() State
+	Snapshot() Snapshot
+	Subscribe() (<-chan Event, func())
+	ForceOpen()
+	ForceClosed()
+	Disable()
+	Reset()
+	TransitionToHalfOpen()
 	Close()
 }
 
@@ -38,24 +81,28 @@ type circuitBreaker struct {
 	postpadding      [56]byte
 	clock            Clock
 	probeSem         chan struct{}
+	window           window
 	failureCount     atomic.Int64
 	successCount     atomic.Int64
+	slowCount        atomic.Int64
+	fallbacks        atomic.Int64
+	fallbackFailures atomic.Int64
+	timedOutCalls    atomic.Int64
+	canceledCalls    atomic.Int64
+	cooldownStrategy CooldownStrategy
 	cooldown         int64
+	consecutiveOpens int64
 	halfOpenWhen     atomic.Int64
+	transitionMu     sync.Mutex
+	events           *eventHub
 	cancelTransition context.CancelFunc
 }
 
 func (cb *circuitBreaker) monitorStateTransitions(ctx context.Context) {
-	windowTicker := time.NewTicker(time.Duration(cb.config.windowSize))
-	defer windowTicker.Stop()
-
 	for {
 		select {
-		case <-windowTicker.C:
-			if State(cb.state.Load()) == Closed {
-				cb.failureCount.Store(0)
-				cb.successCount.Store(0)
-			}
+		case ev := <-cb.events.queue:
+			cb.events.dispatch(ev)
 		case <-ctx.Done():
 			return
 		}
@@ -64,11 +111,9 @@ func (cb *circuitBreaker) monitorStateTransitions(ctx context.Context) {
 
 // New creates a new circuit breaker with the given options.
 func New(opts ...Option) (CircuitBreaker, error) {
-	c := defaultConfig()
-	for _, opt := range opts {
-		if err := opt(&c); err != nil {
-			return nil, fmt.Errorf("unable to apply configuration: %w", err)
-		}
+	c, err := newConfig(opts)
+	if err != nil {
+		return nil, err
 	}
 	return newCircuitBreaker(c), nil
 }
@@ -86,7 +131,9 @@ func newCircuitBreaker(c config) *circuitBreaker {
 		config:           c,
 		clock:            c.clock,
 		probeSem:         make(chan struct{}, c.maximumProbes),
-		cooldown:         c.cooldownTimer,
+		window:           c.newWindow(),
+		cooldownStrategy: c.cooldown(),
+		events:           newEventHub(c.onStateChange, c.eventBuffer),
 		cancelTransition: cancel,
 	}
 	r.state.Store(int64(Closed))
@@ -97,53 +144,80 @@ func newCircuitBreaker(c config) *circuitBreaker {
 type allowResult struct {
 	allowed  bool
 	hasProbe bool
-	timer    *time.Timer
+	state    State
+	reason   RejectReason
+	wait     time.Duration
+}
+
+// openError describes a rejected admission.
+func (cb *circuitBreaker) openError(ar allowResult) *OpenError {
+	return &OpenError{Name: cb.config.name, State: ar.state, RetryAfter: ar.wait, Reason: ar.reason}
+}
+
+// probeBackoff spreads out callers competing for half-open probe slots.
+func probeBackoff() time.Duration {
+	return time.Duration(rand.Intn(90)) * time.Millisecond // #nosec G404
 }
 
 func (cb *circuitBreaker) allow() allowResult {
 	state := State(cb.state.Load())
 	switch state {
 	case Closed:
-		return allowResult{allowed: true}
+		return allowResult{allowed: true, state: state}
 	case HalfOpen:
 		select {
 		case cb.probeSem <- struct{}{}:
-			return allowResult{allowed: true, hasProbe: true}
+			return allowResult{allowed: true, hasProbe: true, state: state}
 		default:
-			return allowResult{allowed: false,
-				timer: time.NewTimer(time.Duration(rand.Intn(90)) * time.Millisecond)} // #nosec G404
+			return allowResult{state: state, reason: ReasonProbesExhausted, wait: probeBackoff()}
 		}
 	case Open:
 		halfOpenAt := cb.halfOpenWhen.Load()
 		now := cb.clock.Now().UnixNano()
 		if now >= halfOpenAt {
-			if cb.state.CompareAndSwap(int64(Open), int64(HalfOpen)) {
+			if cb.toState(Open, HalfOpen, nil) {
 				select {
 				case cb.probeSem <- struct{}{}:
-					return allowResult{allowed: true, hasProbe: true}
+					return allowResult{allowed: true, hasProbe: true, state: HalfOpen}
 				default:
 					// Shouldn't happen since we just transitioned
-					return allowResult{allowed: false, timer: time.NewTimer(time.Duration(rand.Intn(90)) * time.Millisecond)} // #nosec G404
+					return allowResult{state: HalfOpen, reason: ReasonProbesExhausted, wait: probeBackoff()}
 				}
 			}
 			// Someone else transitioned, retry
 			return cb.allow()
 		}
-		waitDuration := time.Duration(halfOpenAt - now)
-		return allowResult{allowed: false, timer: time.NewTimer(waitDuration)}
+		return allowResult{state: state, reason: ReasonOpen, wait: time.Duration(halfOpenAt - now)}
+	case ForcedOpen:
+		return allowResult{state: state, reason: ReasonForcedOpen, wait: forcedOpenPollInterval}
 	default:
-		return allowResult{allowed: true}
+		return allowResult{allowed: true, state: state}
 	}
 }
 
+// ExecuteBlocking runs fn, waiting for the breaker to admit the call. A failed call
+// returns its error unless WithRetryPolicy is set, in which case it is retried with
+// backoff until the policy is used up. Errors the breaker does not count as failures,
+// including those after the caller's context is done, are returned without retrying.
 func (cb *circuitBreaker) ExecuteBlocking(
 	ctx context.Context, fn func(context.Context) error) error {
+	r := newRetrier(cb.retryPolicy(nil), cb.clock)
 	for {
-		timer, err := cb.Execute(ctx, fn)
+		timer, o, err := cb.execute(ctx, fn)
 
 		// Handle success/error immediately
 		if timer == nil {
-			return err
+			if err == nil || o != outcomeFailure {
+				return err
+			}
+			wait, err := r.next(err, 0)
+			if err != nil {
+				return err
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
 		}
 
 		// Wait for circuit to potentially allow retry
@@ -160,15 +234,32 @@ func (cb *circuitBreaker) ExecuteBlocking(
 // ExecuteHTTPBlocking executes HTTP requests with circuit breaker protection and automatic retry.
 // It automatically classifies HTTP response codes and retries on retryable failures.
 //
-// Classification:
+// Classification, unless WithHTTPClassifier replaces it:
 // - 2xx/3xx: Success
 // - 408 (Request Timeout), 429 (Too Many Requests), 5xx: Retryable, opens circuit
 // - Other 4xx: Non-retryable, returns immediately without opening circuit
 // - Network errors: Retryable, opens circuit
 //
+// Responses of 400 and above and those classified as failures are returned with an
+// error describing their status.
+//
+// A Retry-After header on a 429 or 503 response, in seconds or as an HTTP-date, is
+// the minimum delay before the next attempt. With WithRetryAfterCooldown it also
+// keeps the circuit open for that long.
+//
+// Retryable failures are retried with backoff, without limit unless WithRetryPolicy
+// is set. Once the policy is used up the error wraps ErrRetriesExhausted.
+//
+// Only requests that are safe to send twice are retried: those using GET, HEAD,
+// OPTIONS, PUT or DELETE, those carrying an Idempotency-Key header, and those that
+// failed to dial and so never left. Any other failed request is returned at once, with
+// its response if there is one, unless WithNonIdempotentRetries is set. The failure
+// is recorded either way.
+//
 // Parameters:
 //   - ctx: Overall deadline context that cancels all retry attempts
-//   - client: HTTP client to use for requests (uses client.Timeout for per-request timeout)
+//   - client: HTTP client to use for requests (WithCallTimeout bounds the wait for headers,
+//     client.Timeout the whole request)
 //   - requestFactory: Function that returns a new *http.Request for each attempt
 //
 // Returns:
@@ -178,6 +269,7 @@ func (cb *circuitBreaker) ExecuteBlocking(
 // Response body handling:
 //   - Success: Returns response with open body, caller must close
 //   - Retryable failure: Drains and closes body before retry
+//   - Retryable failure that is not retried: Returns response with open body, caller must close
 //   - Non-retryable failure: Returns response with open body, caller must close
 //   - No response (network error): Returns nil response
 func (cb *circuitBreaker) ExecuteHTTPBlocking(
@@ -188,57 +280,48 @@ func (cb *circuitBreaker) ExecuteHTTPBlocking(
 	var lastResp *http.Response
 	var lastErr error
 	var wasRetryable bool
+	var pushback time.Duration
 
+	r := newRetrier(cb.retryPolicy(&defaultRetryPolicy), cb.clock)
 	for {
+		// Stop once the caller gives up; its cancellations are not recorded, so the
+		// breaker would never open to slow the loop down.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Create fresh request for this attempt
 		req, err := requestFactory()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		// Apply context to request
+		// Apply context to request. A call timeout only bounds the wait for the
+		// response headers, since the body outlives the call; client.Timeout bounds both.
 		req = req.WithContext(ctx)
 
 		// Attempt execution through circuit breaker
+		lastResp, lastErr, wasRetryable, pushback = nil, nil, false, 0
 		timer, execErr := cb.Execute(ctx, func(attemptCtx context.Context) error {
-			resp, httpErr := client.Do(req)
-
-			// Network error - retryable
-			if httpErr != nil {
-				lastResp = nil
-				lastErr = httpErr
-				wasRetryable = true
-				return httpErr
-			}
-
-			statusCode := resp.StatusCode
+			resp, httpErr := cb.config.sendWithin(attemptCtx, req, client.Do)
 
-			// Success: 2xx, 3xx
-			if statusCode >= 200 && statusCode < 400 {
-				lastResp = resp
-				lastErr = nil
-				wasRetryable = false
+			o := cb.config.classifyHTTP(resp, httpErr)
+			lastResp, lastErr, wasRetryable, pushback = resp, httpError(resp, httpErr, o), false, 0
+			switch o {
+			case HTTPSuccess:
 				return nil
-			}
-
-			// Retryable: 408, 429, 5xx
-			if statusCode == 408 || statusCode == 429 ||
-				(statusCode >= 500 && statusCode <= 599) {
-				// Drain and close body to allow retry
-				_, _ = io.Copy(io.Discard, resp.Body)
-				_ = resp.Body.Close()
-
-				lastResp = nil
-				lastErr = fmt.Errorf("retryable HTTP error: status %d", statusCode)
-				wasRetryable = true
+			case HTTPRetryable:
+				if resp != nil {
+					pushback = retryAfter(resp, cb.clock.Now())
+				}
+				// A request that may have been processed is only resent when that is safe
+				wasRetryable = cb.config.mayResend(req, httpErr)
 				return lastErr // Opens circuit
+			case HTTPNonRetryable:
+				return &decided{o: outcomeFailure, err: lastErr}
+			default:
+				return &decided{o: outcomeIgnored, err: lastErr}
 			}
-
-			// Non-retryable 4xx: return without opening circuit
-			lastResp = resp
-			lastErr = fmt.Errorf("non-retryable HTTP error: status %d", statusCode)
-			wasRetryable = false
-			return nil // Don't open circuit
 		})
 
 		// If Execute returned a timer, circuit is open - wait for it
@@ -253,17 +336,49 @@ func (cb *circuitBreaker) ExecuteHTTPBlocking(
 		}
 
 		// No timer returned - operation completed
+		// A panic returned as an error never assigned the captured results
+		if _, panicked := execErr.(*PanicError); panicked {
+			return nil, execErr
+		}
+		if errors.Is(execErr, ErrCallTimeout) {
+			lastErr = execErr
+		}
+		// The body is read after the call, so a slow body does not count as a timeout
+		if statusErr, ok := lastErr.(*HTTPStatusError); ok {
+			statusErr.Body = bodyExcerpt(lastResp)
+		}
+
 		// Success: return response
 		if execErr == nil {
 			return lastResp, lastErr
 		}
 
+		// The server's Retry-After is the least we wait, and optionally holds the
+		// circuit open for every other caller too
+		if pushback > 0 && cb.config.retryAfterCooldown {
+			cb.openFor(pushback, lastErr)
+		}
+
 		// Error occurred
-		// If retryable, continue to next iteration to check circuit state
 		// If non-retryable, return immediately
 		if !wasRetryable {
 			return lastResp, lastErr
 		}
+
+		// Drain and close body to allow retry
+		if lastResp != nil {
+			_, _ = io.Copy(io.Discard, lastResp.Body)
+			_ = lastResp.Body.Close()
+		}
+
+		// If retryable, back off before the next iteration checks circuit state
+		wait, err := r.next(lastErr, pushback)
+		if err != nil {
+			return nil, err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
 }
 
@@ -288,6 +403,10 @@ func (cb *circuitBreaker) ExecuteHTTPBlocking(
 // Returns:
 //   - interface{}: gRPC response (caller must type assert to specific response type)
 //   - error: nil on success, error if circuit breaker exhausted retries or context cancelled
+//
+// Every failure is retried with backoff, without limit unless WithRetryPolicy is set.
+// Once the policy is used up the error wraps ErrRetriesExhausted. Errors the breaker
+// does not count as failures are returned at once.
 func (cb *circuitBreaker) ExecuteGRPCBlocking(
 	ctx context.Context,
 	fn func(context.Context) (interface{}, error),
@@ -295,6 +414,7 @@ func (cb *circuitBreaker) ExecuteGRPCBlocking(
 	var lastResp interface{}
 	var lastErr error
 
+	r := newRetrier(cb.retryPolicy(&defaultRetryPolicy), cb.clock)
 	for {
 		// Check context before attempting
 		select {
@@ -304,7 +424,8 @@ func (cb *circuitBreaker) ExecuteGRPCBlocking(
 		}
 
 		// Attempt execution through circuit breaker
-		timer, _ := cb.Execute(ctx, func(attemptCtx context.Context) error {
+		lastResp, lastErr = nil, nil
+		timer, o, execErr := cb.execute(ctx, func(attemptCtx context.Context) error {
 			resp, grpcErr := fn(attemptCtx)
 			lastResp = resp
 			lastErr = grpcErr
@@ -322,68 +443,284 @@ func (cb *circuitBreaker) ExecuteGRPCBlocking(
 			}
 		}
 
-		// Operation completed - return if success
+		// A panic returned as an error never assigned the captured results
+		if _, panicked := execErr.(*PanicError); panicked {
+			return nil, execErr
+		}
+
+		// Operation completed - return if success or not worth retrying
 		if lastErr == nil {
 			return lastResp, nil
 		}
+		if o != outcomeFailure {
+			return nil, lastErr
+		}
 
-		// Error - continue to retry (circuit breaker will enforce backoff)
-		continue
+		// Error - back off, then retry (circuit breaker may add its own wait)
+		wait, err := r.next(lastErr, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
 }
 
+// Execute runs fn if the breaker admits the call. When the call is rejected it returns
+// a timer that fires once the breaker may admit calls again, and a nil error.
+// Callers that do not wait on the timer should stop it; Try avoids the timer altogether.
 func (cb *circuitBreaker) Execute(
 	ctx context.Context,
 	fn func(context.Context) error) (*time.Timer, error) {
+	timer, _, err := cb.execute(ctx, fn)
+	return timer, err
+}
+
+// execute is Execute that also reports how an admitted call was counted, so the
+// blocking methods only retry failures.
+func (cb *circuitBreaker) execute(
+	ctx context.Context,
+	fn func(context.Context) error) (*time.Timer, outcome, error) {
 	ar := cb.allow()
 	if !ar.allowed {
-		return ar.timer, nil
+		cb.reject(ar)
+		return time.NewTimer(ar.wait), outcomeIgnored, nil
 	}
 
-	err := fn(ctx)
+	o, err := cb.run(ctx, ar, fn)
+	return nil, o, err
+}
 
+// Try runs fn if the breaker admits the call and returns its error.
+// When the call is rejected it returns an *OpenError matching ErrCircuitOpen.
+func (cb *circuitBreaker) Try(ctx context.Context, fn func(context.Context) error) error {
+	ar := cb.allow()
+	if !ar.allowed {
+		err := cb.openError(ar)
+		cb.reject(ar)
+		return err
+	}
+
+	_, err := cb.run(ctx, ar, fn)
+	return err
+}
+
+func (cb *circuitBreaker) reject(ar allowResult) {
+	if cb.events.enabled() {
+		cb.publish(EventRejected, ar.state, ar.state, cb.openError(ar))
+	}
+}
+
+// run executes an admitted call, records its outcome and returns how it was counted
+// along with the call's error. fn runs under the call timeout, if any, and an error
+// caused by that deadline is wrapped with ErrCallTimeout and counted as a failure.
+// An error returned after the caller's own context is done is ignored unless
+// cancellations are counted. fn may return a *decided to choose the outcome itself.
+// A panic in fn is recorded as a failure and then handled according to the panic
+// policy; the probe slot is released either way.
+func (cb *circuitBreaker) run(ctx context.Context, ar allowResult, fn func(context.Context) error) (o outcome, err error) {
+	t := cb.newTicket(ar)
+	// Settles the ticket if fn exits the goroutine without returning or panicking.
+	defer t.Ignore()
+
+	callCtx := ctx
+	if cb.config.callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeoutCause(ctx, time.Duration(cb.config.callTimeout), ErrCallTimeout)
+		defer cancel()
+	}
+
+	panicked, err := call(callCtx, fn)
+	d, isDecided := err.(*decided)
+	if isDecided {
+		err = d.err
+	}
+	switch {
+	case panicked != nil:
+		o = outcomeFailure
+	case err != nil && callCtx != ctx && context.Cause(callCtx) == ErrCallTimeout:
+		// Only the breaker's own deadline is a timeout; the caller's is not.
+		cb.timedOutCalls.Add(1)
+		err = fmt.Errorf("%w after %v: %w", ErrCallTimeout, time.Duration(cb.config.callTimeout), err)
+		o = outcomeFailure
+	case err != nil && ctx.Err() != nil && !cb.config.countCancellations:
+		// The caller gave up, which says nothing about the dependency.
+		cb.canceledCalls.Add(1)
+		o = outcomeIgnored
+	case isDecided:
+		o = d.o
+	default:
+		o = cb.config.classify(err)
+	}
+
+	t.finish(o, err)
+
+	if panicked != nil && cb.config.panicPolicy == PanicRepanic {
+		panic(panicked.Value)
+	}
+	return o, err
+}
+
+// call runs fn, converting a panic into a *PanicError returned both as the error and
+// separately so it cannot be confused with an error fn returned.
+func call(ctx context.Context, fn func(context.Context) error) (panicked *PanicError, err error) {
+	completed := false
+	defer func() {
+		if !completed {
+			panicked = &PanicError{Value: recover(), Stack: debug.Stack()}
+			err = panicked
+		}
+	}()
+	err = fn(ctx)
+	completed = true
+	return nil, err
+}
+
+// complete marks a classified call as slow if it took too long and records it.
+// cause is the call's error when it counts as a failure.
+func (cb *circuitBreaker) complete(start time.Time, s sample, cause error) {
+	if cb.config.slowCallThreshold > 0 {
+		if elapsed := cb.clock.Now().Sub(start); elapsed >= time.Duration(cb.config.slowCallThreshold) {
+			s |= sampleSlow
+			if cause == nil {
+				cause = fmt.Errorf("%w: took %v", ErrSlowCall, elapsed)
+			}
+		}
+	}
+	cb.record(s, cause)
+}
+
+// record counts the outcome of a completed call and applies any resulting transition.
+// cause is reported as the reason if the breaker opens.
+func (cb *circuitBreaker) record(s sample, cause error) {
 	state := State(cb.state.Load())
 
-	if err != nil {
-		failures := cb.failureCount.Add(1)
+	switch state {
+	case Closed:
+		cb.window.record(s)
+
+		if s&(sampleFailure|sampleSlow) != 0 && cb.shouldTrip(cb.window.counts()) {
+			cb.toState(Closed, Open, cause)
+		}
+		return
+	case Disabled:
+		cb.window.record(s)
+		return
+	case ForcedOpen, ForcedClosed:
+		return
+	}
+
+	if s&sampleSlow != 0 {
+		cb.slowCount.Add(1)
+	}
+
+	// A slow probe is as bad as a failed one when slow calls can open the circuit.
+	failed := s&sampleFailure != 0 || (s&sampleSlow != 0 && cb.config.slowCallRate > 0)
+	if failed {
+		cb.failureCount.Add(1)
 
-		if state == Closed && failures >= cb.config.failureThreshold {
-			cb.toState(Open)
-		} else if state == HalfOpen {
-			cb.toState(Open)
+		if state == HalfOpen {
+			cb.toState(HalfOpen, Open, cause)
 		}
 	} else {
 		successes := cb.successCount.Add(1)
 
 		if state == HalfOpen && successes >= cb.config.successToClose {
-			cb.toState(Closed)
+			cb.toState(HalfOpen, Closed, nil)
 		}
 	}
+}
 
-	if ar.hasProbe {
-		cb.releaseProbe()
+// shouldTrip reports whether the closed-state window warrants opening the circuit.
+func (cb *circuitBreaker) shouldTrip(counts windowCounts) bool {
+	if cb.config.slowCallRate > 0 && counts.calls >= cb.config.minimumCalls &&
+		percentage(counts.slow, counts.calls) >= cb.config.slowCallRate {
+		return true
+	}
+	if cb.config.failureRate > 0 {
+		return counts.calls >= cb.config.minimumCalls &&
+			percentage(counts.failures, counts.calls) >= cb.config.failureRate
 	}
+	return counts.failures >= cb.config.failureThreshold
+}
 
-	return nil, err
+// percentage returns part as a percentage of total, or 0 when total is 0.
+func percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
 }
 
 func (cb *circuitBreaker) releaseProbe() {
 	<-cb.probeSem
 }
 
-func (cb *circuitBreaker) toState(newState State) {
-	cb.state.Store(int64(newState))
+// toState moves the breaker from one state to another and resets the counters.
+// It reports false, without side effects, if the breaker is no longer in from.
+func (cb *circuitBreaker) toState(from, to State, cause error) bool {
+	cb.transitionMu.Lock()
+	if !cb.state.CompareAndSwap(int64(from), int64(to)) {
+		cb.transitionMu.Unlock()
+		return false
+	}
+	cb.enterStateLocked(to)
+	cb.transitionMu.Unlock()
+
+	cb.publish(EventStateChange, from, to, cause)
+	return true
+}
+
+// openFor opens the breaker for d because the dependency asked callers to back off.
+// A breaker that is already open stays open until the later of its own cooldown and
+// d from now. Forced and disabled breakers are left alone.
+func (cb *circuitBreaker) openFor(d time.Duration, cause error) {
+	cb.transitionMu.Lock()
+	from := State(cb.state.Load())
+	until := cb.clock.Now().Add(d).UnixNano()
+	switch from {
+	case Closed, HalfOpen:
+		cb.state.Store(int64(Open))
+		cb.enterStateLocked(Open)
+		cb.cooldown = int64(d)
+		cb.halfOpenWhen.Store(until)
+	case Open:
+		if until > cb.halfOpenWhen.Load() {
+			cb.halfOpenWhen.Store(until)
+		}
+	}
+	cb.transitionMu.Unlock()
+
+	if from == Closed || from == HalfOpen {
+		cb.publish(EventStateChange, from, Open, cause)
+	}
+}
+
+// enterStateLocked resets the bookkeeping for a state the breaker just entered.
+// The caller must hold transitionMu.
+func (cb *circuitBreaker) enterStateLocked(to State) {
+	cb.window.reset()
 	cb.failureCount.Store(0)
 	cb.successCount.Store(0)
-	if newState == Open {
-		halfOpenAt := cb.clock.Now().Add(time.Duration(cb.cooldown)).UnixNano()
+	cb.slowCount.Store(0)
+	switch to {
+	case Open:
+		cb.consecutiveOpens++
+		cooldown := cb.cooldownStrategy.Cooldown(cb.consecutiveOpens, time.Duration(cb.cooldown))
+		cb.cooldown = int64(cooldown)
+		halfOpenAt := cb.clock.Now().Add(cooldown).UnixNano()
 		cb.halfOpenWhen.Store(halfOpenAt)
+	case Closed:
+		cb.consecutiveOpens = 0
+		cb.cooldown = 0
 	}
 }
 
-// Close stops the background state monitoring goroutine.
+// Close stops the background state monitoring goroutine and closes all event subscriptions.
 func (cb *circuitBreaker) Close() {
 	if cb.cancelTransition != nil {
 		cb.cancelTransition()
 	}
+	cb.events.close()
 }
@@ -0,0 +1,105 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCallerCancellationIsNotRecorded(t *testing.T) {
+	cb, err := NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = cb.Try(ctx, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the call's own error, got %v", err)
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != Closed {
+		t.Errorf("Caller cancellation should not open the circuit, got %v", snap.State)
+	}
+	if snap.Failures != 0 || snap.Successes != 0 {
+		t.Errorf("Caller cancellation should not be counted, got %d failures and %d successes", snap.Failures, snap.Successes)
+	}
+	if snap.CanceledCalls != 1 {
+		t.Errorf("Expected 1 canceled call, got %d", snap.CanceledCalls)
+	}
+}
+
+func TestCallerCancellationReleasesProbe(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb.Try(ctx, func(ctx context.Context) error {
+		return errors.New("upstream read aborted")
+	})
+
+	snap := cb.Snapshot()
+	if snap.State != HalfOpen {
+		t.Errorf("Canceled probe should not reopen the circuit, got %v", snap.State)
+	}
+	if snap.ProbesInUse != 0 || snap.Failures != 0 {
+		t.Errorf("Canceled probe should release its slot uncounted, got %d in use and %d failures",
+			snap.ProbesInUse, snap.Failures)
+	}
+}
+
+func TestCountCancellations(t *testing.T) {
+	cb, err := NewZeroTolerance(WithCountCancellations())
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb.Try(ctx, func(ctx context.Context) error { return ctx.Err() })
+
+	if snap := cb.Snapshot(); snap.State != Open || snap.CanceledCalls != 0 {
+		t.Errorf("Counted cancellation should open the circuit, got %v with %d canceled", snap.State, snap.CanceledCalls)
+	}
+}
+
+func TestExecuteHTTPBlocking_CanceledContextStopsRetrying(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	_, err = cb.ExecuteHTTPBlocking(ctx, http.DefaultClient, func() (*http.Request, error) {
+		attempts++
+		return http.NewRequest("GET", "http://127.0.0.1:0", nil)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if attempts != 0 {
+		t.Errorf("Expected no attempts with a canceled context, got %d", attempts)
+	}
+}
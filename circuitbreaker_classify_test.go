@@ -0,0 +1,124 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestIsFailureCountsRejectedErrorsAsSuccess(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithIsFailure(func(err error) bool { return !errors.Is(err, errNotFound) }),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for range 3 {
+		err := cb.Try(context.Background(), func(ctx context.Context) error {
+			return errNotFound
+		})
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("Expected the call's own error, got %v", err)
+		}
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != Closed {
+		t.Errorf("Non-failure errors should not open the circuit, got %v", snap.State)
+	}
+	if snap.Successes != 3 || snap.Failures != 0 {
+		t.Errorf("Expected 3 successes and 0 failures, got %d and %d", snap.Successes, snap.Failures)
+	}
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	if cb.State() != Open {
+		t.Errorf("Other errors should still open the circuit, got %v", cb.State())
+	}
+}
+
+func TestIgnoredErrorsAreNotCounted(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithIgnoredErrors(context.Canceled),
+		WithIgnoredErrors(errNotFound),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	wrapped := errors.Join(errors.New("lookup"), errNotFound)
+	err = cb.Try(context.Background(), func(ctx context.Context) error { return wrapped })
+	if err != wrapped {
+		t.Errorf("Expected the call's own error, got %v", err)
+	}
+	cb.Try(context.Background(), func(ctx context.Context) error { return context.Canceled })
+
+	snap := cb.Snapshot()
+	if snap.State != Closed {
+		t.Errorf("Ignored errors should not open the circuit, got %v", snap.State)
+	}
+	if snap.Successes != 0 || snap.Failures != 0 {
+		t.Errorf("Ignored errors should not be counted, got %d successes and %d failures",
+			snap.Successes, snap.Failures)
+	}
+}
+
+func TestIgnoredErrorsTakePrecedence(t *testing.T) {
+	cb, err := NewZeroTolerance(
+		WithIsFailure(func(error) bool { return true }),
+		WithIgnoredErrors(errNotFound),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error { return errNotFound })
+	if cb.State() != Closed {
+		t.Errorf("Ignored error should win over the failure predicate, got %v", cb.State())
+	}
+}
+
+func TestIgnoredProbeReleasesSlot(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithIgnoredErrors(errNotFound))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	cb.Try(context.Background(), func(ctx context.Context) error { return errNotFound })
+
+	snap := cb.Snapshot()
+	if snap.State != HalfOpen {
+		t.Errorf("Ignored probe should leave the breaker half-open, got %v", snap.State)
+	}
+	if snap.ProbesInUse != 0 || snap.Successes != 0 || snap.Failures != 0 {
+		t.Errorf("Ignored probe should release its slot without counting, got %+v", snap)
+	}
+}
+
+func TestClassificationValidation(t *testing.T) {
+	if _, err := New(WithIsFailure(nil)); err == nil {
+		t.Error("Expected error for nil failure predicate")
+	}
+	if _, err := New(WithIgnoredErrors(errNotFound, nil)); err == nil {
+		t.Error("Expected error for nil ignored error")
+	}
+}
@@ -0,0 +1,122 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExponentialCooldownOnRepeatedOpens(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithSuccessToClose(1),
+		WithCooldownStrategy(ExponentialCooldown(10*time.Second, 30*time.Second)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	failure := func(ctx context.Context) error { return errors.New("simulated failure") }
+
+	cb.Execute(context.Background(), failure)
+	for i, want := range []time.Duration{20 * time.Second, 30 * time.Second, 30 * time.Second} {
+		snap := cb.Snapshot()
+		fakeClock.Advance(snap.RetryAfter(fakeClock.Now()))
+
+		// Failed probe reopens the circuit with a longer cooldown
+		cb.Execute(context.Background(), failure)
+
+		snap = cb.Snapshot()
+		if snap.State != Open {
+			t.Fatalf("Reopen %d: expected open state, got %v", i+1, snap.State)
+		}
+		if snap.ConsecutiveOpens != int64(i+2) {
+			t.Errorf("Reopen %d: expected %d consecutive opens, got %d", i+1, i+2, snap.ConsecutiveOpens)
+		}
+		if snap.Cooldown != want {
+			t.Errorf("Reopen %d: expected cooldown %v, got %v", i+1, want, snap.Cooldown)
+		}
+		if got := snap.RetryAfter(fakeClock.Now()); got != want {
+			t.Errorf("Reopen %d: expected half-open in %v, got %v", i+1, want, got)
+		}
+	}
+
+	// Closing resets the backoff
+	fakeClock.Advance(30 * time.Second)
+	cb.Execute(context.Background(), func(ctx context.Context) error { return nil })
+	if snap := cb.Snapshot(); snap.State != Closed || snap.ConsecutiveOpens != 0 {
+		t.Fatalf("Expected closed state with no consecutive opens, got %v with %d", snap.State, snap.ConsecutiveOpens)
+	}
+
+	cb.Execute(context.Background(), failure)
+	if snap := cb.Snapshot(); snap.Cooldown != 10*time.Second {
+		t.Errorf("Expected base cooldown after closing, got %v", snap.Cooldown)
+	}
+}
+
+func TestDefaultCooldownIsConstant(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithCooldownTimer(5*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for range 3 {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+		if got := cb.Snapshot().Cooldown; got != 5*time.Second {
+			t.Errorf("Expected constant 5s cooldown, got %v", got)
+		}
+		fakeClock.Advance(5 * time.Second)
+	}
+
+	if got := cb.Snapshot().ConsecutiveOpens; got != 3 {
+		t.Errorf("Expected 3 consecutive opens, got %d", got)
+	}
+}
+
+func TestCooldownStrategies(t *testing.T) {
+	base, maximum := 100*time.Millisecond, time.Second
+
+	if got := ConstantCooldown(base).Cooldown(7, time.Hour); got != base {
+		t.Errorf("ConstantCooldown: expected %v, got %v", base, got)
+	}
+
+	exponential := ExponentialCooldown(base, maximum)
+	for opens, want := range map[int64]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		80: time.Second,
+	} {
+		if got := exponential.Cooldown(opens, 0); got != want {
+			t.Errorf("ExponentialCooldown(%d): expected %v, got %v", opens, want, got)
+		}
+	}
+
+	fullJitter := FullJitterCooldown(base, maximum)
+	decorrelated := DecorrelatedJitterCooldown(base, maximum)
+	for range 1000 {
+		if got := fullJitter.Cooldown(3, 0); got < 0 || got > 400*time.Millisecond {
+			t.Fatalf("FullJitterCooldown(3): %v outside [0, 400ms]", got)
+		}
+		if got := decorrelated.Cooldown(1, 0); got < base || got > 300*time.Millisecond {
+			t.Fatalf("DecorrelatedJitterCooldown first open: %v outside [100ms, 300ms]", got)
+		}
+		if got := decorrelated.Cooldown(5, 800*time.Millisecond); got < base || got > maximum {
+			t.Fatalf("DecorrelatedJitterCooldown: %v outside [100ms, 1s]", got)
+		}
+	}
+}
+
+func TestCooldownStrategyValidation(t *testing.T) {
+	if _, err := New(WithCooldownStrategy(nil)); err == nil {
+		t.Error("Expected error for nil cooldown strategy")
+	}
+}
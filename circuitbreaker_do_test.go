@@ -0,0 +1,124 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID   int
+	Name string
+}
+
+func TestDoReturnsTypedResult(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	got, err := Do(context.Background(), cb, func(ctx context.Context) (*item, error) {
+		return &item{ID: 7, Name: "widget"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if got.ID != 7 || got.Name != "widget" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	count, err := Do(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 3, errors.New("partial failure")
+	})
+	if err == nil || count != 3 {
+		t.Errorf("Expected fn's result and error, got %d and %v", count, err)
+	}
+	if snap := cb.Snapshot(); snap.Successes != 1 || snap.Failures != 1 {
+		t.Errorf("Expected 1 success and 1 failure recorded, got %d and %d", snap.Successes, snap.Failures)
+	}
+}
+
+func TestDoRejectedReturnsZeroValue(t *testing.T) {
+	cb, err := NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.ForceOpen()
+	got, err := Do(context.Background(), cb, func(ctx context.Context) (*item, error) {
+		t.Error("Function should not run while open")
+		return &item{}, nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected zero value on rejection, got %+v", got)
+	}
+}
+
+func TestDoBlockingWaitsForCircuit(t *testing.T) {
+	cb, err := NewZeroTolerance(WithCooldownTimer(50*time.Millisecond), WithSuccessToClose(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := DoBlocking(ctx, cb, func(ctx context.Context) (string, error) {
+		return "recovered", nil
+	})
+	if err != nil || got != "recovered" {
+		t.Errorf("Expected recovered result after cooldown, got %q and %v", got, err)
+	}
+
+	cb.ForceOpen()
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	got, err = DoBlocking(short, cb, func(ctx context.Context) (string, error) {
+		return "unexpected", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || got != "" {
+		t.Errorf("Expected deadline exceeded and zero value, got %q and %v", got, err)
+	}
+}
+
+func TestDoGRPCBlockingRetriesUntilSuccess(t *testing.T) {
+	cb, err := New(WithFailureThreshold(5))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	got, err := DoGRPCBlocking(context.Background(), cb, func(ctx context.Context) ([]item, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("unavailable")
+		}
+		return []item{{ID: 1}, {ID: 2}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if len(got) != 2 || attempts != 3 {
+		t.Errorf("Expected 2 items after 3 attempts, got %d items after %d", len(got), attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stale, err := DoGRPCBlocking(ctx, cb, func(ctx context.Context) ([]item, error) {
+		return []item{{ID: 9}}, nil
+	})
+	if !errors.Is(err, context.Canceled) || stale != nil {
+		t.Errorf("Expected cancellation and zero value, got %v and %v", stale, err)
+	}
+}
@@ -0,0 +1,112 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTryReturnsFunctionResult(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	if err := cb.Try(context.Background(), func(ctx context.Context) error {
+		return nil
+	}); err != nil {
+		t.Errorf("Expected nil error on success, got %v", err)
+	}
+
+	expectedErr := errors.New("simulated failure")
+	err = cb.Try(context.Background(), func(ctx context.Context) error {
+		return expectedErr
+	})
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Expected function error, got %v", err)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Error("Function error should not match ErrCircuitOpen")
+	}
+}
+
+func TestTryRejectsWhenOpen(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithCooldownTimer(60*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(15 * time.Second)
+
+	executed := false
+	err = cb.Try(context.Background(), func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+
+	if executed {
+		t.Error("Function should not run while circuit is open")
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	var openErr *OpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("Expected *OpenError, got %T", err)
+	}
+	if openErr.State != Open {
+		t.Errorf("Expected open state, got %v", openErr.State)
+	}
+	if openErr.Reason != ReasonOpen {
+		t.Errorf("Expected ReasonOpen, got %v", openErr.Reason)
+	}
+	if openErr.RetryAfter != 45*time.Second {
+		t.Errorf("Expected 45s retry-after, got %v", openErr.RetryAfter)
+	}
+}
+
+func TestTryRejectsWhenProbesExhausted(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	var innerErr error
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		// The only probe slot is held by this call.
+		innerErr = cb.Try(ctx, func(ctx context.Context) error {
+			return nil
+		})
+		return nil
+	})
+
+	var openErr *OpenError
+	if !errors.As(innerErr, &openErr) {
+		t.Fatalf("Expected *OpenError, got %v", innerErr)
+	}
+	if openErr.State != HalfOpen {
+		t.Errorf("Expected half-open state, got %v", openErr.State)
+	}
+	if openErr.Reason != ReasonProbesExhausted {
+		t.Errorf("Expected ReasonProbesExhausted, got %v", openErr.Reason)
+	}
+	if openErr.RetryAfter >= 90*time.Millisecond {
+		t.Errorf("Expected short retry-after for probe contention, got %v", openErr.RetryAfter)
+	}
+}
@@ -0,0 +1,172 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stateChange struct {
+	from, to State
+	cause    error
+}
+
+func TestOnStateChangeListener(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	changes := make(chan stateChange, 10)
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithSuccessToClose(1),
+		WithOnStateChange(func(from, to State, cause error) {
+			changes <- stateChange{from, to, cause}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	failure := errors.New("simulated failure")
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return failure
+	})
+	fakeClock.Advance(121 * time.Second)
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	expected := []stateChange{
+		{Closed, Open, failure},
+		{Open, HalfOpen, nil},
+		{HalfOpen, Closed, nil},
+	}
+	for i, want := range expected {
+		select {
+		case got := <-changes:
+			if got.from != want.from || got.to != want.to || !errors.Is(got.cause, want.cause) {
+				t.Errorf("Change %d: expected %v->%v (%v), got %v->%v (%v)",
+					i+1, want.from, want.to, want.cause, got.from, got.to, got.cause)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Change %d: listener was not called", i+1)
+		}
+	}
+}
+
+func TestSubscribeReceivesEvents(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithName("payments"))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	events, cancel := cb.Subscribe()
+	defer cancel()
+
+	failure := errors.New("simulated failure")
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return failure
+	})
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	fakeClock.Advance(121 * time.Second)
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return failure
+	})
+
+	expected := []EventType{
+		EventStateChange, // closed -> open
+		EventRejected,
+		EventStateChange, // open -> half-open
+		EventProbeStart,
+		EventStateChange, // half-open -> open
+		EventProbeFinish,
+	}
+	for i, want := range expected {
+		select {
+		case ev := <-events:
+			if ev.Type != want {
+				t.Fatalf("Event %d: expected %v, got %v", i+1, want, ev.Type)
+			}
+			if ev.Name != "payments" {
+				t.Errorf("Event %d: expected name payments, got %q", i+1, ev.Name)
+			}
+			if ev.Type == EventRejected && !errors.Is(ev.Err, ErrCircuitOpen) {
+				t.Errorf("Rejected event should carry ErrCircuitOpen, got %v", ev.Err)
+			}
+			if ev.Type == EventProbeFinish && !errors.Is(ev.Err, failure) {
+				t.Errorf("Probe finish event should carry the probe error, got %v", ev.Err)
+			}
+		default:
+			t.Fatalf("Event %d: expected %v, got nothing", i+1, want)
+		}
+	}
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithEventBufferSize(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	_, cancel := cb.Subscribe()
+	defer cancel()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		for range 10 {
+			timer, _ := cb.Execute(context.Background(), func(ctx context.Context) error {
+				return nil
+			})
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Execute blocked on a subscriber that is not reading")
+	}
+
+	if dropped := cb.Snapshot().DroppedEvents; dropped != 10 {
+		t.Errorf("Expected 10 dropped events, got %d", dropped)
+	}
+}
+
+func TestSubscriptionClosed(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+
+	first, cancel := cb.Subscribe()
+	second, _ := cb.Subscribe()
+
+	cancel()
+	cancel() // Cancelling twice is harmless
+	if _, ok := <-first; ok {
+		t.Error("Cancelled subscription channel should be closed")
+	}
+
+	cb.Close()
+	if _, ok := <-second; ok {
+		t.Error("Subscription channel should be closed when the breaker is closed")
+	}
+
+	late, _ := cb.Subscribe()
+	if _, ok := <-late; ok {
+		t.Error("Subscribing to a closed breaker should return a closed channel")
+	}
+}
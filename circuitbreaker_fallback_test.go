@@ -0,0 +1,124 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFallbackOnRejection(t *testing.T) {
+	cb, err := NewZeroTolerance(WithName("pricing"))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.ForceOpen()
+
+	var cause error
+	err = cb.ExecuteWithFallback(context.Background(),
+		func(ctx context.Context) error {
+			t.Error("Function should not run while open")
+			return nil
+		},
+		func(ctx context.Context, err error) error {
+			cause = err
+			return nil
+		})
+	if err != nil {
+		t.Errorf("Expected fallback result, got %v", err)
+	}
+
+	var openErr *OpenError
+	if !errors.As(cause, &openErr) || openErr.Name != "pricing" {
+		t.Errorf("Fallback should receive the rejection, got %v", cause)
+	}
+	if snap := cb.Snapshot(); snap.Fallbacks != 1 || snap.FallbackFailures != 0 {
+		t.Errorf("Expected 1 fallback and no fallback failures, got %d and %d", snap.Fallbacks, snap.FallbackFailures)
+	}
+}
+
+func TestFallbackOnFailure(t *testing.T) {
+	cb, err := New(WithFailureThreshold(5))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	upstream := errors.New("upstream down")
+	degraded := errors.New("cache miss")
+
+	var cause error
+	err = cb.ExecuteWithFallback(context.Background(),
+		func(ctx context.Context) error { return upstream },
+		func(ctx context.Context, err error) error {
+			cause = err
+			return degraded
+		})
+	if err != degraded {
+		t.Errorf("Expected the fallback's error, got %v", err)
+	}
+	if cause != upstream {
+		t.Errorf("Fallback should receive the call's error, got %v", cause)
+	}
+
+	snap := cb.Snapshot()
+	if snap.Failures != 1 {
+		t.Errorf("Failed call should still be recorded, got %d failures", snap.Failures)
+	}
+	if snap.Fallbacks != 1 || snap.FallbackFailures != 1 {
+		t.Errorf("Expected 1 fallback and 1 fallback failure, got %d and %d", snap.Fallbacks, snap.FallbackFailures)
+	}
+}
+
+func TestFallbackSkippedForNonFailures(t *testing.T) {
+	cb, err := New(
+		WithIsFailure(func(err error) bool { return !errors.Is(err, errNotFound) }),
+		WithIgnoredErrors(context.Canceled),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	fallback := func(ctx context.Context, err error) error {
+		t.Errorf("Fallback should not run for %v", err)
+		return nil
+	}
+	for _, want := range []error{nil, errNotFound, context.Canceled} {
+		got := cb.ExecuteWithFallback(context.Background(),
+			func(ctx context.Context) error { return want }, fallback)
+		if got != want {
+			t.Errorf("Expected %v to be returned as-is, got %v", want, got)
+		}
+	}
+
+	if snap := cb.Snapshot(); snap.Fallbacks != 0 {
+		t.Errorf("Expected no fallbacks, got %d", snap.Fallbacks)
+	}
+}
+
+func TestDoWithFallback(t *testing.T) {
+	cb, err := NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	fetch := func(ctx context.Context) (int, error) { return 0, errors.New("timeout") }
+	cached := func(ctx context.Context, cause error) (int, error) { return 42, nil }
+
+	for i := range 2 {
+		got, err := DoWithFallback(context.Background(), cb, fetch, cached)
+		if err != nil || got != 42 {
+			t.Errorf("Call %d: expected cached 42, got %d and %v", i+1, got, err)
+		}
+	}
+
+	if cb.State() != Open {
+		t.Errorf("Expected failure to open the circuit, got %v", cb.State())
+	}
+	if snap := cb.Snapshot(); snap.Fallbacks != 2 {
+		t.Errorf("Expected 2 fallbacks, got %d", snap.Fallbacks)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -840,6 +842,8 @@ func TestExecuteHTTPBlocking_PostWithBody(t *testing.T) {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
+		// POST is only retried when the server can deduplicate it
+		req.Header.Set("Idempotency-Key", "order-42")
 		return req, nil
 	}
 
@@ -865,3 +869,768 @@ func TestExecuteHTTPBlocking_PostWithBody(t *testing.T) {
 		}
 	}
 }
+
+// slowServer answers /slow-headers after delay and sends /slow-body's headers at once
+// but its body only after delay.
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-body" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte("late"))
+	}))
+}
+
+func TestExecuteHTTPBlocking_CallTimeoutBoundsHeaders(t *testing.T) {
+	server := slowServer(200 * time.Millisecond)
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithCallTimeout(10*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL+"/slow-headers", nil)
+	})
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrCallTimeout) {
+		t.Errorf("Expected the slow headers to time out, got %v", err)
+	}
+	if snap := cb.Snapshot(); snap.TimedOutCalls != 1 || snap.Failures != 1 {
+		t.Errorf("Expected 1 timed-out failure, got %d timeouts and %d failures", snap.TimedOutCalls, snap.Failures)
+	}
+}
+
+func TestExecuteHTTPBlocking_CallTimeoutLeavesBodyReadable(t *testing.T) {
+	server := slowServer(50 * time.Millisecond)
+	defer server.Close()
+
+	cb, err := New(WithCallTimeout(10 * time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL+"/slow-body", nil)
+	})
+	if err != nil {
+		t.Fatalf("Expected the headers to arrive in time, got %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "late" {
+		t.Errorf("Expected the body to stay readable after the call, got %q and %v", body, err)
+	}
+	if snap := cb.Snapshot(); snap.TimedOutCalls != 0 {
+		t.Errorf("A slow body should not count as a timeout, got %d", snap.TimedOutCalls)
+	}
+}
+
+func TestTransportCallTimeoutBoundsHeaders(t *testing.T) {
+	server := slowServer(50 * time.Millisecond)
+	defer server.Close()
+
+	transport, err := NewTransport(nil,
+		WithKeyFunc(func(r *http.Request) string { return r.URL.Path }),
+		WithBreakerOptions(WithCallTimeout(10*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	if _, err := client.Get(server.URL + "/slow-headers"); !errors.Is(err, ErrCallTimeout) {
+		t.Errorf("Expected the slow headers to time out, got %v", err)
+	}
+
+	resp, err := client.Get(server.URL + "/slow-body")
+	if err != nil {
+		t.Fatalf("Expected the headers to arrive in time, got %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "late" {
+		t.Errorf("Expected the body to stay readable after the call, got %q and %v", body, err)
+	}
+}
+
+func TestRetryAfterParsing(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+	}{
+		{"delta seconds", http.StatusTooManyRequests, "120", 2 * time.Minute},
+		{"padded delta seconds", http.StatusServiceUnavailable, " 3 ", 3 * time.Second},
+		{"http date", http.StatusServiceUnavailable, now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"date in the past", http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"negative", http.StatusTooManyRequests, "-5", 0},
+		{"malformed", http.StatusTooManyRequests, "soon", 0},
+		{"missing", http.StatusTooManyRequests, "", 0},
+		{"capped", http.StatusTooManyRequests, "99999999999", maxRetryAfter},
+		{"other status", http.StatusInternalServerError, "120", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			if got := retryAfter(resp, now); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecuteHTTPBlockingWaitsForRetryAfter(t *testing.T) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cb, err := New(WithFailureThreshold(10))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := cb.ExecuteHTTPBlocking(ctx, &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if err != nil {
+		t.Fatalf("Expected success after Retry-After, got %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected to wait at least the Retry-After delay, got %v", elapsed)
+	}
+	if cb.State() != Closed {
+		t.Errorf("Retry-After should not open the circuit by default, got %v", cb.State())
+	}
+}
+
+func TestRetryAfterCooldownOpensCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(10),
+		WithRetryAfterCooldown(),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	_, err = cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected ErrRetriesExhausted, got %v", err)
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != Open {
+		t.Fatalf("Expected Retry-After to open the circuit, got %v", snap.State)
+	}
+	if got := snap.RetryAfter(fakeClock.Now()); got != 30*time.Second {
+		t.Errorf("Expected the circuit to stay open for 30s, got %v", got)
+	}
+	if snap.Cooldown != 30*time.Second {
+		t.Errorf("Expected a 30s cooldown, got %v", snap.Cooldown)
+	}
+}
+
+func TestRetryAfterCooldownKeepsLongerCooldown(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithRetryAfterCooldown())
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	cb.(*circuitBreaker).openFor(5*time.Second, nil)
+
+	if got := cb.Snapshot().RetryAfter(fakeClock.Now()); got != 120*time.Second {
+		t.Errorf("A shorter Retry-After should not cut the cooldown, got %v", got)
+	}
+
+	cb.(*circuitBreaker).openFor(10*time.Minute, nil)
+	if got := cb.Snapshot().RetryAfter(fakeClock.Now()); got != 10*time.Minute {
+		t.Errorf("A longer Retry-After should extend the cooldown, got %v", got)
+	}
+}
+
+func TestTransportRetryAfterCooldown(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	fakeClock := &FakeClock{now: time.Now()}
+	transport, err := NewTransport(nil, WithBreakerOptions(
+		WithClock(fakeClock),
+		WithFailureThreshold(10),
+		WithRetryAfterCooldown(),
+	))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the 429 response, got %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen while the server asked to back off, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request to reach the server, got %d", requests)
+	}
+
+	fakeClock.Advance(31 * time.Second)
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected a probe after Retry-After elapsed, got %v", err)
+	}
+	resp.Body.Close()
+	if requests != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", requests)
+	}
+}
+
+func TestDefaultHTTPClassifier(t *testing.T) {
+	tests := []struct {
+		status int
+		want   HTTPOutcome
+	}{
+		{http.StatusOK, HTTPSuccess},
+		{http.StatusNotModified, HTTPSuccess},
+		{http.StatusNotFound, HTTPSuccess},
+		{http.StatusRequestTimeout, HTTPRetryable},
+		{http.StatusTooManyRequests, HTTPRetryable},
+		{http.StatusInternalServerError, HTTPRetryable},
+		{http.StatusNotImplemented, HTTPRetryable},
+	}
+	for _, tt := range tests {
+		if got := DefaultHTTPClassifier(&http.Response{StatusCode: tt.status}, nil); got != tt.want {
+			t.Errorf("Status %d: expected outcome %d, got %d", tt.status, tt.want, got)
+		}
+	}
+	if got := DefaultHTTPClassifier(nil, errors.New("connection refused")); got != HTTPRetryable {
+		t.Errorf("Expected transport errors to be retryable, got %d", got)
+	}
+}
+
+func TestStatusClassifier(t *testing.T) {
+	classify := StatusClassifier(
+		Status(http.StatusConflict, HTTPRetryable),
+		Status(http.StatusNotImplemented, HTTPNonRetryable),
+		StatusRange(400, 499, HTTPIgnore),
+		StatusRange(409, 409, HTTPNonRetryable),
+	)
+	tests := []struct {
+		status int
+		want   HTTPOutcome
+	}{
+		{http.StatusConflict, HTTPRetryable},
+		{http.StatusNotImplemented, HTTPNonRetryable},
+		{http.StatusNotFound, HTTPIgnore},
+		{http.StatusBadGateway, HTTPRetryable},
+		{http.StatusOK, HTTPSuccess},
+	}
+	for _, tt := range tests {
+		if got := classify(&http.Response{StatusCode: tt.status}, nil); got != tt.want {
+			t.Errorf("Status %d: expected outcome %d, got %d", tt.status, tt.want, got)
+		}
+	}
+	if got := classify(nil, errors.New("connection refused")); got != HTTPRetryable {
+		t.Errorf("Expected transport errors to fall back to the default, got %d", got)
+	}
+}
+
+func TestExecuteHTTPBlockingRetriesErrorEnvelope(t *testing.T) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.Header().Set("X-Error", "temporarily unavailable")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}),
+		WithHTTPClassifier(func(resp *http.Response, err error) HTTPOutcome {
+			if err == nil && resp.Header.Get("X-Error") != "" {
+				return HTTPRetryable
+			}
+			return DefaultHTTPClassifier(resp, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retrying the error envelope, got %v", err)
+	}
+	resp.Body.Close()
+
+	if attempt != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempt)
+	}
+	if snap := cb.Snapshot(); snap.Failures != 1 || snap.Successes != 1 {
+		t.Errorf("Expected 1 failure and 1 success, got %d and %d", snap.Failures, snap.Successes)
+	}
+}
+
+func TestExecuteHTTPBlockingNonRetryableFailure(t *testing.T) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer server.Close()
+
+	cb, err := NewZeroTolerance(WithHTTPClassifier(StatusClassifier(
+		Status(http.StatusNotImplemented, HTTPNonRetryable),
+	)))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if err == nil || err.Error() != "non-retryable HTTP error: status 501" {
+		t.Errorf("Expected non-retryable error, got %v", err)
+	}
+	if resp == nil {
+		t.Fatal("Expected the 501 response, got nil")
+	}
+	resp.Body.Close()
+
+	if attempt != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempt)
+	}
+	if cb.State() != Open {
+		t.Errorf("Expected the non-retryable failure to open the circuit, got %v", cb.State())
+	}
+}
+
+func TestExecuteHTTPBlockingIgnoredResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cb, err := NewZeroTolerance(WithHTTPClassifier(StatusClassifier(
+		StatusRange(400, 499, HTTPIgnore),
+	)))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if err == nil || err.Error() != "non-retryable HTTP error: status 404" {
+		t.Errorf("Expected non-retryable error, got %v", err)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	if snap := cb.Snapshot(); snap.Failures != 0 || snap.Successes != 0 {
+		t.Errorf("Ignored responses should not be counted, got %d failures and %d successes",
+			snap.Failures, snap.Successes)
+	}
+}
+
+func TestTransportUsesHTTPClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(nil, WithBreakerOptions(
+		WithFailureThreshold(1),
+		WithHTTPClassifier(StatusClassifier(Status(http.StatusConflict, HTTPRetryable))),
+	))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the 409 response unchanged, got %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the classified 409 to open the circuit, got %v", err)
+	}
+}
+
+func TestTransportIgnoredRetryAfterKeepsCircuitClosed(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(nil, WithBreakerOptions(
+		WithRetryAfterCooldown(),
+		WithHTTPClassifier(StatusClassifier(Status(http.StatusTooManyRequests, HTTPIgnore))),
+	))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected the ignored 429 to pass through, got %v", err)
+		}
+		resp.Body.Close()
+	}
+	if requests != 2 {
+		t.Errorf("Expected both requests to reach the server, got %d", requests)
+	}
+}
+
+func TestHTTPClassifierValidation(t *testing.T) {
+	if _, err := New(WithHTTPClassifier(nil)); err == nil {
+		t.Error("Expected error for nil HTTP classifier")
+	}
+}
+
+func TestExecuteHTTPBlockingReturnsHTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc123")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("missing widget"))
+	}))
+	defer server.Close()
+
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	target := strings.Replace(server.URL, "http://", "http://user:secret@", 1) + "/widgets/7"
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", target, nil)
+	})
+	if resp == nil {
+		t.Fatal("Expected the 404 response, got nil")
+	}
+	defer resp.Body.Close()
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *HTTPStatusError, got %T: %v", err, err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || statusErr.Retryable {
+		t.Errorf("Expected a non-retryable 404, got %d (retryable %v)", statusErr.StatusCode, statusErr.Retryable)
+	}
+	if statusErr.Method != "GET" || !strings.HasSuffix(statusErr.URL, "/widgets/7") {
+		t.Errorf("Expected the request method and URL, got %s %s", statusErr.Method, statusErr.URL)
+	}
+	if strings.Contains(statusErr.URL, "secret") {
+		t.Errorf("Expected the password to be redacted, got %s", statusErr.URL)
+	}
+	if statusErr.Header.Get("X-Request-Id") != "abc123" {
+		t.Errorf("Expected the response headers, got %v", statusErr.Header)
+	}
+	if string(statusErr.Body) != "missing widget" {
+		t.Errorf("Expected the body excerpt, got %q", statusErr.Body)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "missing widget" {
+		t.Errorf("Expected the caller to still read the full body, got %q", body)
+	}
+}
+
+func TestExhaustedRetriesWrapHTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(strings.Repeat("x", 4*maxBodyExcerpt)))
+	}))
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("PUT", server.URL, strings.NewReader("payload"))
+	})
+	if resp != nil {
+		resp.Body.Close()
+		t.Error("Expected nil response once retries are exhausted")
+	}
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected ErrRetriesExhausted, got %v", err)
+	}
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected the last *HTTPStatusError to be wrapped, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusServiceUnavailable || !statusErr.Retryable || statusErr.Method != "PUT" {
+		t.Errorf("Unexpected status error %+v", statusErr)
+	}
+	if len(statusErr.Body) != maxBodyExcerpt {
+		t.Errorf("Expected a %d byte excerpt, got %d bytes", maxBodyExcerpt, len(statusErr.Body))
+	}
+}
+
+// failingTransport fails the first failures round trips with err before using base.
+type failingTransport struct {
+	base     http.RoundTripper
+	err      error
+	failures int
+	calls    int
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, t.err
+	}
+	return t.base.RoundTrip(req)
+}
+
+func TestMayResend(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	tests := []struct {
+		name   string
+		method string
+		key    string
+		err    error
+		want   bool
+	}{
+		{"GET", http.MethodGet, "", readErr, true},
+		{"empty method means GET", "", "", readErr, true},
+		{"HEAD", http.MethodHead, "", nil, true},
+		{"OPTIONS", http.MethodOptions, "", nil, true},
+		{"PUT", http.MethodPut, "", readErr, true},
+		{"DELETE", http.MethodDelete, "", nil, true},
+		{"POST", http.MethodPost, "", nil, false},
+		{"PATCH after read error", http.MethodPatch, "", readErr, false},
+		{"POST with idempotency key", http.MethodPost, "order-42", nil, true},
+		{"POST after dial error", http.MethodPost, "", dialErr, true},
+	}
+	var c config
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "http://example.com", nil)
+			// NewRequest fills in GET, but a request built by hand may leave it empty
+			req.Method = tt.method
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			if got := c.mayResend(req, tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecuteHTTPBlockingDoesNotResendPost(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("order failed"))
+	}))
+	defer server.Close()
+
+	cb, err := New(WithFailureThreshold(10))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader(`{"item":7}`))
+	})
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected the 500 status error, got %v", err)
+	}
+	if resp == nil {
+		t.Fatal("Expected the 500 response to be returned, got nil")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "order failed" {
+		t.Errorf("Expected the response body to stay readable, got %q", body)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected the POST to be sent once, got %d", requests)
+	}
+	if snap := cb.Snapshot(); snap.Failures != 1 {
+		t.Errorf("Expected the failure to be recorded, got %d failures", snap.Failures)
+	}
+}
+
+func TestExecuteHTTPBlockingNonIdempotentRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithNonIdempotentRetries(),
+		WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader(`{"item":7}`))
+	})
+	if err != nil {
+		t.Fatalf("Expected the POST to be retried, got %v", err)
+	}
+	resp.Body.Close()
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestExecuteHTTPBlockingResendsPostThatNeverLeft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	newRequest := func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader(`{"item":7}`))
+	}
+
+	dialFailure := &failingTransport{
+		base:     http.DefaultTransport,
+		err:      &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+		failures: 1,
+	}
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{Transport: dialFailure}, newRequest)
+	if err != nil {
+		t.Fatalf("Expected a dial failure to be retried, got %v", err)
+	}
+	resp.Body.Close()
+	if dialFailure.calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", dialFailure.calls)
+	}
+
+	resetFailure := &failingTransport{
+		base:     http.DefaultTransport,
+		err:      &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+		failures: 1,
+	}
+	_, err = cb.ExecuteHTTPBlocking(context.Background(), &http.Client{Transport: resetFailure}, newRequest)
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Expected the connection reset to be returned, got %v", err)
+	}
+	if resetFailure.calls != 1 {
+		t.Errorf("Expected a request that may have been processed to be sent once, got %d", resetFailure.calls)
+	}
+
+	if snap := cb.Snapshot(); snap.Failures != 2 {
+		t.Errorf("Expected both network failures to be recorded, got %d", snap.Failures)
+	}
+}
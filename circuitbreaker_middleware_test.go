@@ -0,0 +1,244 @@
+package circuitbreaker
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareShedsLoadWhenOpen(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithCooldownTimer(90*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	calls := 0
+	handler := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/orders", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected the handler's 500, got %d", rec.Code)
+	}
+	if cb.State() != Open {
+		t.Fatalf("Expected 5xx to open the circuit, got %v", cb.State())
+	}
+
+	fakeClock.Advance(500 * time.Millisecond)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/orders", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while open, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "90" {
+		t.Errorf("Expected Retry-After rounded up to 90, got %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("Handler should not run while open, ran %d times", calls)
+	}
+}
+
+func TestMiddlewareCountsPanicsAndSlowHandlers(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(5),
+		WithSlowCallThreshold(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) { panic("nil handler") })
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		fakeClock.Advance(2 * time.Second)
+		w.Write([]byte("eventually"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+	handler := Middleware(cb)(mux)
+
+	func() {
+		defer func() {
+			if v := recover(); v == nil {
+				t.Error("Expected the panic to propagate to the server")
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	snap := cb.Snapshot()
+	if snap.Failures != 1 || snap.Successes != 2 || snap.SlowCalls != 1 {
+		t.Errorf("Expected 1 failure, 2 successes and 1 slow call, got %d, %d and %d",
+			snap.Failures, snap.Successes, snap.SlowCalls)
+	}
+}
+
+func TestMiddlewarePanicReturnError(t *testing.T) {
+	cb, err := New(WithPanicPolicy(PanicReturnError))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	handler := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for a recovered panic, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewarePerRouteBreakers(t *testing.T) {
+	registry, err := NewRegistry(WithFailureThreshold(1))
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	handler := Middleware(nil,
+		WithRegistry(registry),
+		WithKeyFunc(func(r *http.Request) string { return r.Method + " " + r.URL.Path }),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/reports" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/reports", nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/reports", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the failing route to be shed, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected other routes to be served, got %d", rec.Code)
+	}
+
+	reports, _ := registry.Get("GET /reports")
+	if reports.State() != Open {
+		t.Errorf("Expected the route's breaker to be open, got %v", reports.State())
+	}
+}
+
+func TestMiddlewareAppliesCallTimeout(t *testing.T) {
+	cb, err := New(WithCallTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	handler := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("Expected the handler's context to carry the call timeout")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestMiddlewarePreservesWriterInterfaces(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	handler := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Expected flush to reach the recorder, got %v", err)
+		}
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("Expected the wrapped writer to implement http.Hijacker")
+		}
+		if _, _, err := hj.Hijack(); err != nil {
+			t.Errorf("Expected hijack to reach the underlying writer, got %v", err)
+		}
+	}))
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+	if !rec.Flushed || !rec.hijacked {
+		t.Errorf("Expected flush and hijack to be forwarded, got flushed=%v hijacked=%v", rec.Flushed, rec.hijacked)
+	}
+
+	// A writer without Hijack support reports it instead of failing silently
+	plain := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("Expected http.ErrNotSupported, got %v", err)
+		}
+	}))
+	plain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestMiddlewareValidation(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic without a breaker or registry")
+		}
+	}()
+	Middleware(nil)
+}
+
+func TestMiddlewareRejectsBreakerOptions(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for WithBreakerOptions, which Middleware would ignore")
+		}
+	}()
+	Middleware(cb, WithBreakerOptions(WithFailureThreshold(1)))
+}
+
+func TestMiddlewareRecordsFinalStatusAfterEarlyHints(t *testing.T) {
+	cb, err := NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	handler := Middleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</app.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if cb.State() != Open {
+		t.Errorf("Expected the 500 after 103 Early Hints to open the circuit, got %v", cb.State())
+	}
+}
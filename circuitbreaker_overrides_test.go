@@ -0,0 +1,216 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestForceOpenRejectsUntilReset(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.ForceOpen()
+	if cb.State() != ForcedOpen {
+		t.Fatalf("Expected forced-open state, got %v", cb.State())
+	}
+
+	// Cooldown never releases a forced breaker
+	fakeClock.Advance(time.Hour)
+	err = cb.Try(context.Background(), func(ctx context.Context) error {
+		t.Error("Function should not run while forced open")
+		return nil
+	})
+	var openErr *OpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("Expected *OpenError, got %v", err)
+	}
+	if openErr.Reason != ReasonForcedOpen || openErr.State != ForcedOpen {
+		t.Errorf("Expected forced-open rejection, got %v in %v", openErr.Reason, openErr.State)
+	}
+
+	cb.Reset()
+	if err := cb.Try(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("Expected call to be admitted after reset, got %v", err)
+	}
+}
+
+func TestForceClosedIgnoresFailures(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.ForceClosed()
+	for range 5 {
+		err := cb.Try(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+		if errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("Forced-closed breaker should admit every call")
+		}
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != ForcedClosed {
+		t.Errorf("Expected forced-closed state, got %v", snap.State)
+	}
+	if snap.Failures != 0 {
+		t.Errorf("Forced-closed breaker should not record failures, got %d", snap.Failures)
+	}
+}
+
+func TestDisableKeepsRecording(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Disable()
+	for range 3 {
+		cb.Try(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+	}
+	cb.Try(context.Background(), func(ctx context.Context) error { return nil })
+
+	snap := cb.Snapshot()
+	if snap.State != Disabled {
+		t.Errorf("Disabled breaker should not open, got %v", snap.State)
+	}
+	if snap.Failures != 3 || snap.Successes != 1 {
+		t.Errorf("Expected 3 failures and 1 success recorded, got %d and %d", snap.Failures, snap.Successes)
+	}
+
+	cb.Reset()
+	if snap := cb.Snapshot(); snap.State != Closed || snap.Failures != 0 {
+		t.Errorf("Expected closed state with cleared counters, got %v with %d failures", snap.State, snap.Failures)
+	}
+}
+
+func TestResetClearsOpenState(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithCooldownStrategy(ExponentialCooldown(time.Second, time.Minute)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	cb.Reset()
+
+	snap := cb.Snapshot()
+	if snap.State != Closed || !snap.HalfOpenAt.IsZero() || snap.ConsecutiveOpens != 0 {
+		t.Errorf("Expected a fresh closed breaker, got %+v", snap)
+	}
+}
+
+func TestTransitionToHalfOpen(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithSuccessToClose(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.ForceOpen()
+	cb.TransitionToHalfOpen()
+	if cb.State() != HalfOpen {
+		t.Fatalf("Expected half-open state, got %v", cb.State())
+	}
+
+	var probes int
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		probes = cb.Snapshot().ProbesInUse
+		return nil
+	})
+	if probes != 1 {
+		t.Errorf("Call after manual half-open should hold a probe, got %d in use", probes)
+	}
+	if cb.State() != Closed {
+		t.Errorf("Successful probe should close the breaker, got %v", cb.State())
+	}
+}
+
+func TestForceOpenReleasesInFlightProbe(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithSuccessToClose(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		cb.ForceOpen()
+		return nil
+	})
+
+	snap := cb.Snapshot()
+	if snap.State != ForcedOpen {
+		t.Errorf("Probe outcome should not override a forced state, got %v", snap.State)
+	}
+	if snap.ProbesInUse != 0 {
+		t.Errorf("Probe slot should be released, got %d in use", snap.ProbesInUse)
+	}
+
+	cb.TransitionToHalfOpen()
+	if err := cb.Try(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("Probe slot should be available after release, got %v", err)
+	}
+}
+
+func TestOverridesPublishStateChanges(t *testing.T) {
+	cb, err := New(WithName("inventory"))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	events, cancel := cb.Subscribe()
+	defer cancel()
+
+	cb.ForceOpen()
+	cb.ForceOpen() // No change, no event
+	cb.Disable()
+	cb.Reset()
+
+	expected := []struct{ from, to State }{
+		{Closed, ForcedOpen},
+		{ForcedOpen, Disabled},
+		{Disabled, Closed},
+	}
+	for i, want := range expected {
+		select {
+		case ev := <-events:
+			if ev.Type != EventStateChange || ev.From != want.from || ev.To != want.to {
+				t.Errorf("Event %d: expected %v->%v, got %v %v->%v", i+1, want.from, want.to, ev.Type, ev.From, ev.To)
+			}
+		default:
+			t.Fatalf("Event %d: expected %v->%v, got nothing", i+1, want.from, want.to)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("Unexpected extra event %v %v->%v", ev.Type, ev.From, ev.To)
+	default:
+	}
+}
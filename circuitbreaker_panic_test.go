@@ -0,0 +1,191 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicReleasesProbeAndRepanics(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	func() {
+		defer func() {
+			if v := recover(); v != "nil handler" {
+				t.Errorf("Expected original panic value, got %v", v)
+			}
+		}()
+		cb.Try(context.Background(), func(ctx context.Context) error {
+			panic("nil handler")
+		})
+	}()
+
+	snap := cb.Snapshot()
+	if snap.ProbesInUse != 0 {
+		t.Errorf("Panicking probe should release its slot, got %d in use", snap.ProbesInUse)
+	}
+	if snap.State != Open || snap.ConsecutiveOpens != 2 {
+		t.Errorf("Panicking probe should reopen the circuit, got %v after %d opens", snap.State, snap.ConsecutiveOpens)
+	}
+
+	// The breaker is not wedged: the next probe is admitted after the cooldown
+	fakeClock.Advance(121 * time.Second)
+	ran := false
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if !ran {
+		t.Error("Expected a new probe to be admitted after the panic")
+	}
+}
+
+func TestPanicReturnErrorPolicy(t *testing.T) {
+	cb, err := New(WithPanicPolicy(PanicReturnError), WithIsFailure(func(error) bool { return false }))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	err = cb.Try(context.Background(), func(ctx context.Context) error {
+		var m map[string]int
+		m["boom"]++
+		return nil
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected *PanicError, got %v", err)
+	}
+	if !strings.Contains(panicErr.Error(), "nil map") {
+		t.Errorf("Expected panic value in error, got %q", panicErr.Error())
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestPanicReturnErrorPolicy") {
+		t.Error("Expected stack trace of the panicking call")
+	}
+	if snap := cb.Snapshot(); snap.Failures != 1 {
+		t.Errorf("Panics are failures regardless of WithIsFailure, got %d failures", snap.Failures)
+	}
+}
+
+func TestExecuteGRPCBlockingReturnsPanicError(t *testing.T) {
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithPanicPolicy(PanicReturnError),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	resp, err := cb.ExecuteGRPCBlocking(context.Background(), func(ctx context.Context) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			return "stale", errors.New("unavailable")
+		}
+		panic("boom")
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || resp != nil {
+		t.Errorf("Expected *PanicError and no response, got %v and %v", err, resp)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected the panic to end the retries, got %d attempts", attempts)
+	}
+}
+
+func TestDoGRPCBlockingReturnsPanicError(t *testing.T) {
+	cb, err := New(WithPanicPolicy(PanicReturnError))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	got, err := DoGRPCBlocking(context.Background(), cb, func(ctx context.Context) (string, error) {
+		panic("boom")
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || got != "" {
+		t.Errorf("Expected *PanicError and the zero value, got %v and %q", err, got)
+	}
+}
+
+// panickingTransport panics on every round trip.
+type panickingTransport struct{}
+
+func (panickingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("boom")
+}
+
+func TestExecuteHTTPBlockingReturnsPanicError(t *testing.T) {
+	cb, err := New(WithPanicPolicy(PanicReturnError))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{Transport: panickingTransport{}},
+		func() (*http.Request, error) {
+			return http.NewRequest("GET", "http://example.com", nil)
+		})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || resp != nil {
+		t.Errorf("Expected *PanicError and no response, got %v and %v", err, resp)
+	}
+	if snap := cb.Snapshot(); snap.Failures != 1 {
+		t.Errorf("Expected the panic to be recorded as a failure, got %d failures", snap.Failures)
+	}
+}
+
+func TestPanicErrorUnwrap(t *testing.T) {
+	cause := errors.New("corrupt state")
+	err := error(&PanicError{Value: cause})
+	if !errors.Is(err, cause) {
+		t.Error("PanicError should unwrap an error panic value")
+	}
+	if errors.Unwrap(&PanicError{Value: "text"}) != nil {
+		t.Error("PanicError should not unwrap a non-error panic value")
+	}
+}
+
+func TestPanicTriggersFallback(t *testing.T) {
+	cb, err := New(WithPanicPolicy(PanicReturnError))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	got, err := DoWithFallback(context.Background(), cb,
+		func(ctx context.Context) (string, error) { panic("boom") },
+		func(ctx context.Context, cause error) (string, error) {
+			var panicErr *PanicError
+			if !errors.As(cause, &panicErr) {
+				t.Errorf("Fallback should receive the *PanicError, got %v", cause)
+			}
+			return "fallback", nil
+		})
+	if err != nil || got != "fallback" {
+		t.Errorf("Expected fallback result, got %q and %v", got, err)
+	}
+}
+
+func TestPanicPolicyValidation(t *testing.T) {
+	if _, err := New(WithPanicPolicy(PanicPolicy(7))); err == nil {
+		t.Error("Expected error for unknown panic policy")
+	}
+}
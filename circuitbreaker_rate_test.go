@@ -0,0 +1,145 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFailureRateRequiresMinimumCalls(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureRateThreshold(50),
+		WithMinimumCalls(5),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for i := range 4 {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+		if cb.State() != Closed {
+			t.Fatalf("Call %d: breaker should not open below the minimum number of calls", i+1)
+		}
+	}
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	if cb.State() != Open {
+		t.Errorf("Breaker should open once minimum calls are reached at 100%% failures, got %v", cb.State())
+	}
+}
+
+func TestFailureRateScalesWithVolume(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureRateThreshold(50),
+		WithMinimumCalls(10),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for range 100 {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+	}
+	for i := range 99 {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+		if cb.State() != Closed {
+			t.Fatalf("Breaker opened after %d failures in %d calls", i+1, 100+i+1)
+		}
+	}
+
+	snap := cb.Snapshot()
+	if rate := snap.FailureRate(); rate >= 50 || rate < 49 {
+		t.Errorf("Expected failure rate just under 50%%, got %.2f", rate)
+	}
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	if cb.State() != Open {
+		t.Errorf("Breaker should open at 100 failures in 200 calls, got %v", cb.State())
+	}
+}
+
+func TestFailureRateIgnoresAbsoluteThreshold(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithFailureRateThreshold(50),
+		WithMinimumCalls(4),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	if cb.State() != Closed {
+		t.Errorf("Rate threshold should replace the zero tolerance threshold, got %v", cb.State())
+	}
+}
+
+func TestFailureRateWithSlidingCountWindow(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithSlidingCountWindow(4),
+		WithFailureRateThreshold(75),
+		WithMinimumCalls(4),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	outcomes := []error{nil, nil, errors.New("fail"), nil, errors.New("fail")}
+	for _, outcome := range outcomes {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return outcome
+		})
+	}
+	if cb.State() != Closed {
+		t.Fatalf("Breaker should be closed at 50%% failures over the last 4 calls, got %v", cb.State())
+	}
+
+	// Evicts the oldest success
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("fail")
+	})
+	if cb.State() != Open {
+		t.Errorf("Breaker should open at 75%% failures over the last 4 calls, got %v", cb.State())
+	}
+}
+
+func TestFailureRateValidation(t *testing.T) {
+	for _, percent := range []float64{0, -1, 101} {
+		if _, err := New(WithFailureRateThreshold(percent)); err == nil {
+			t.Errorf("Expected error for failure rate %v", percent)
+		}
+	}
+	if _, err := New(WithMinimumCalls(0)); err == nil {
+		t.Error("Expected error for zero minimum calls")
+	}
+	if _, err := New(WithSlidingCountWindow(5), WithFailureRateThreshold(50), WithMinimumCalls(6)); err == nil {
+		t.Error("Expected error when minimum calls exceed the sliding window size")
+	}
+	if _, err := New(WithSlidingCountWindow(2), WithFailureRateThreshold(50)); err == nil {
+		t.Error("Expected error when the default minimum calls exceed the sliding window size")
+	}
+}
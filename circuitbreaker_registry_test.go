@@ -0,0 +1,159 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryReturnsSameBreaker(t *testing.T) {
+	registry, err := NewRegistry(WithFailureThreshold(2))
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	var wg sync.WaitGroup
+	breakers := make([]CircuitBreaker, 10)
+	for i := range breakers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			breakers[i], _ = registry.Get("payments")
+		}()
+	}
+	wg.Wait()
+
+	for i, cb := range breakers {
+		if cb == nil || cb != breakers[0] {
+			t.Fatalf("Get %d returned a different breaker", i)
+		}
+	}
+	if name := breakers[0].Name(); name != "payments" {
+		t.Errorf("Expected breaker named payments, got %q", name)
+	}
+	if threshold := breakers[0].Snapshot().Settings.FailureThreshold; threshold != 2 {
+		t.Errorf("Expected default failure threshold 2, got %d", threshold)
+	}
+}
+
+func TestRegistryOverrides(t *testing.T) {
+	registry, err := NewRegistry(WithFailureThreshold(2), WithCooldownTimer(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	registry.Override("search", WithFailureThreshold(10), WithName("ignored"))
+
+	search, err := registry.Get("search")
+	if err != nil {
+		t.Fatalf("Failed to get breaker: %v", err)
+	}
+	settings := search.Snapshot().Settings
+	if settings.FailureThreshold != 10 || settings.CooldownTimer != time.Minute {
+		t.Errorf("Expected override on top of defaults, got %+v", settings)
+	}
+	if search.Name() != "search" {
+		t.Errorf("Registry key should name the breaker, got %q", search.Name())
+	}
+
+	other, _ := registry.Get("inventory")
+	if threshold := other.Snapshot().Settings.FailureThreshold; threshold != 2 {
+		t.Errorf("Override should only apply to its name, got threshold %d", threshold)
+	}
+
+	registry.Override("broken", WithFailureThreshold(0))
+	if _, err := registry.Get("broken"); err == nil {
+		t.Error("Expected error for invalid override")
+	}
+}
+
+func TestRegistryNamesRangeRemove(t *testing.T) {
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	for _, name := range []string{"users", "billing", "search"} {
+		registry.Get(name)
+	}
+	if names := registry.Names(); !slices.Equal(names, []string{"billing", "search", "users"}) {
+		t.Errorf("Expected sorted names, got %v", names)
+	}
+
+	var visited []string
+	registry.Range(func(name string, cb CircuitBreaker) bool {
+		visited = append(visited, cb.Name())
+		return name != "search"
+	})
+	if !slices.Equal(visited, []string{"billing", "search"}) {
+		t.Errorf("Expected Range to stop after search, got %v", visited)
+	}
+
+	billing, _ := registry.Get("billing")
+	billing.ForceOpen()
+	if !registry.Remove("billing") {
+		t.Error("Expected billing to be removed")
+	}
+	if registry.Remove("billing") {
+		t.Error("Removing twice should report false")
+	}
+
+	fresh, _ := registry.Get("billing")
+	if fresh == billing || fresh.State() != Closed {
+		t.Errorf("Expected a fresh closed breaker after removal, got %v", fresh.State())
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	cb, _ := registry.Get("users")
+	events, cancel := cb.Subscribe()
+	defer cancel()
+
+	registry.Close()
+
+	if _, ok := <-events; ok {
+		t.Error("Expected breaker subscriptions to be closed with the registry")
+	}
+	if _, err := registry.Get("users"); !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Expected ErrRegistryClosed, got %v", err)
+	}
+	if names := registry.Names(); len(names) != 0 {
+		t.Errorf("Expected no breakers after close, got %v", names)
+	}
+}
+
+func TestRegistryNamesErrors(t *testing.T) {
+	registry, err := NewRegistry(WithFailureThreshold(1))
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	cb, _ := registry.Get("ledger")
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	var openErr *OpenError
+	err = cb.Try(context.Background(), func(ctx context.Context) error { return nil })
+	if !errors.As(err, &openErr) || openErr.Name != "ledger" {
+		t.Errorf("Expected rejection labelled with the registry name, got %v", err)
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	if _, err := NewRegistry(WithFailureThreshold(0)); err == nil {
+		t.Error("Expected error for invalid default options")
+	}
+}
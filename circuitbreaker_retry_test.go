@@ -0,0 +1,272 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteBlockingWithoutPolicyDoesNotRetry(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	simulated := errors.New("simulated failure")
+	err = cb.ExecuteBlocking(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return simulated
+	})
+	if err != simulated || attempts != 1 {
+		t.Errorf("Expected the first error after 1 attempt, got %v after %d", err, attempts)
+	}
+}
+
+func TestExecuteBlockingRetriesUntilMaxAttempts(t *testing.T) {
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	simulated := errors.New("simulated failure")
+	err = cb.ExecuteBlocking(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return simulated
+	})
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if !errors.Is(err, ErrRetriesExhausted) || !errors.Is(err, simulated) {
+		t.Errorf("Expected ErrRetriesExhausted wrapping the last error, got %v", err)
+	}
+
+	attempts = 0
+	err = cb.ExecuteBlocking(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return simulated
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Expected success on the second attempt, got %v after %d", err, attempts)
+	}
+}
+
+func TestExecuteBlockingDoesNotRetryNonFailures(t *testing.T) {
+	errBusinessRule := errors.New("business rule violated")
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithIgnoredErrors(errNotFound),
+		WithIsFailure(func(err error) bool { return !errors.Is(err, errBusinessRule) }),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for _, want := range []error{errNotFound, errBusinessRule} {
+		attempts := 0
+		err = cb.ExecuteBlocking(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return want
+		})
+		if err != want || attempts != 1 {
+			t.Errorf("Expected %v after 1 attempt, got %v after %d", want, err, attempts)
+		}
+
+		attempts = 0
+		_, err = cb.ExecuteGRPCBlocking(context.Background(), func(ctx context.Context) (interface{}, error) {
+			attempts++
+			return nil, want
+		})
+		if err != want || attempts != 1 {
+			t.Errorf("Expected %v from ExecuteGRPCBlocking after 1 attempt, got %v after %d", want, err, attempts)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err = cb.ExecuteBlocking(ctx, func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("Expected the cancellation after 1 attempt, got %v after %d", err, attempts)
+	}
+}
+
+func TestRetryPolicyRetryablePredicate(t *testing.T) {
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{
+			InitialBackoff: time.Millisecond,
+			Retryable:      func(err error) bool { return !errors.Is(err, errNotFound) },
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	_, err = cb.ExecuteGRPCBlocking(context.Background(), func(ctx context.Context) (interface{}, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("unavailable")
+		}
+		return nil, errNotFound
+	})
+	if err != errNotFound {
+		t.Errorf("Expected the non-retryable error itself, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyBacksOffWhileClosed(t *testing.T) {
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 4, InitialBackoff: 20 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	start := time.Now()
+	_, err = cb.ExecuteGRPCBlocking(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("unavailable")
+	})
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected ErrRetriesExhausted, got %v", err)
+	}
+	// 20ms + 40ms + 80ms between the four attempts.
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Expected at least 140ms of backoff, got %v", elapsed)
+	}
+	if cb.State() != Closed {
+		t.Errorf("Expected the breaker to stay closed, got %v", cb.State())
+	}
+}
+
+func TestRetryPolicyMaxElapsedTime(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond, MaxElapsedTime: 2500 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	attempts := 0
+	err = cb.ExecuteBlocking(context.Background(), func(ctx context.Context) error {
+		attempts++
+		fakeClock.Advance(time.Second)
+		return errors.New("slow failure")
+	})
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected ErrRetriesExhausted, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts within the elapsed time budget, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyRejectionsAreNotAttempts(t *testing.T) {
+	cb, err := NewZeroTolerance(
+		WithCooldownTimer(30*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	attempts := 0
+	start := time.Now()
+	err = cb.ExecuteBlocking(ctx, func(ctx context.Context) error {
+		attempts++
+		return errors.New("simulated failure")
+	})
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected ErrRetriesExhausted, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 admitted attempts, got %d", attempts)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected the second attempt to wait for the cooldown, got %v", elapsed)
+	}
+}
+
+func TestExecuteHTTPBlockingRetriesExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cb, err := New(
+		WithFailureThreshold(10),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	resp, err := cb.ExecuteHTTPBlocking(context.Background(), &http.Client{}, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	})
+	if resp != nil {
+		resp.Body.Close()
+		t.Error("Expected nil response once retries are exhausted")
+	}
+	if !errors.Is(err, ErrRetriesExhausted) || !strings.Contains(err.Error(), "retryable HTTP error: status 503") {
+		t.Errorf("Expected ErrRetriesExhausted wrapping the last status, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyValidation(t *testing.T) {
+	invalid := []RetryPolicy{
+		{MaxAttempts: -1},
+		{InitialBackoff: -time.Second},
+		{MaxBackoff: -time.Second},
+		{Multiplier: 0.5},
+		{Jitter: 1.5},
+		{MaxElapsedTime: -time.Second},
+	}
+	for _, p := range invalid {
+		if _, err := New(WithRetryPolicy(p)); err == nil {
+			t.Errorf("Expected error for retry policy %+v", p)
+		}
+	}
+	if _, err := New(WithRetryPolicy(RetryPolicy{})); err != nil {
+		t.Errorf("Expected the zero retry policy to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func slowCall(clock *FakeClock, d time.Duration) func(context.Context) error {
+	return func(ctx context.Context) error {
+		clock.Advance(d)
+		return nil
+	}
+}
+
+func TestSlowCallsOpenCircuit(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithSlowCallThreshold(time.Second),
+		WithSlowCallRateThreshold(50),
+		WithMinimumCalls(4),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	events, cancel := cb.Subscribe()
+	defer cancel()
+
+	cb.Execute(context.Background(), slowCall(fakeClock, 10*time.Millisecond))
+	cb.Execute(context.Background(), slowCall(fakeClock, 10*time.Millisecond))
+	cb.Execute(context.Background(), slowCall(fakeClock, 2*time.Second))
+	if cb.State() != Closed {
+		t.Fatalf("Breaker should stay closed below minimum calls, got %v", cb.State())
+	}
+
+	_, err = cb.Execute(context.Background(), slowCall(fakeClock, 2*time.Second))
+	if err != nil {
+		t.Errorf("Slow call should still return its own result, got %v", err)
+	}
+	if cb.State() != Open {
+		t.Fatalf("Breaker should open at 50%% slow calls, got %v", cb.State())
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != EventStateChange || !errors.Is(ev.Err, ErrSlowCall) {
+			t.Errorf("Expected state change caused by ErrSlowCall, got %v (%v)", ev.Type, ev.Err)
+		}
+	default:
+		t.Error("Expected a state change event")
+	}
+}
+
+func TestSlowCallsCountedInSnapshot(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(WithClock(fakeClock), WithSlowCallThreshold(time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), slowCall(fakeClock, 3*time.Second))
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		fakeClock.Advance(time.Second)
+		return errors.New("slow failure")
+	})
+	cb.Execute(context.Background(), slowCall(fakeClock, time.Millisecond))
+
+	snap := cb.Snapshot()
+	if snap.State != Closed {
+		t.Errorf("Slow calls alone should not open without a slow call rate, got %v", snap.State)
+	}
+	if snap.SlowCalls != 2 {
+		t.Errorf("Expected 2 slow calls, got %d", snap.SlowCalls)
+	}
+	if snap.Failures != 1 || snap.Successes != 2 {
+		t.Errorf("Expected 1 failure and 2 successes, got %d and %d", snap.Failures, snap.Successes)
+	}
+	if rate := snap.SlowCallRate(); rate < 66 || rate > 67 {
+		t.Errorf("Expected slow call rate of about 66.7%%, got %.2f", rate)
+	}
+}
+
+func TestSlowProbeReopensCircuit(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(
+		WithClock(fakeClock),
+		WithSlowCallThreshold(time.Second),
+		WithSlowCallRateThreshold(100),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	cb.Execute(context.Background(), slowCall(fakeClock, 5*time.Second))
+	if cb.State() != Open {
+		t.Errorf("Slow probe should reopen the circuit, got %v", cb.State())
+	}
+}
+
+func TestSlowCallValidation(t *testing.T) {
+	if _, err := New(WithSlowCallThreshold(0)); err == nil {
+		t.Error("Expected error for zero slow call threshold")
+	}
+	if _, err := New(WithSlowCallRateThreshold(150)); err == nil {
+		t.Error("Expected error for slow call rate above 100%")
+	}
+	if _, err := New(WithSlowCallRateThreshold(50)); err == nil {
+		t.Error("Expected error for slow call rate without a slow call threshold")
+	}
+}
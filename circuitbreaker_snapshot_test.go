@@ -0,0 +1,149 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStateReflectsTransitions(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithSuccessToClose(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	if cb.State() != Closed {
+		t.Fatalf("New breaker should be closed, got %v", cb.State())
+	}
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	if cb.State() != Open {
+		t.Fatalf("Breaker should be open after failure, got %v", cb.State())
+	}
+
+	fakeClock.Advance(121 * time.Second)
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		if cb.State() != HalfOpen {
+			t.Errorf("Probe should run in half-open state, got %v", cb.State())
+		}
+		return nil
+	})
+	if cb.State() != Closed {
+		t.Errorf("Breaker should close after successful probe, got %v", cb.State())
+	}
+}
+
+func TestSnapshotCounters(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(WithClock(fakeClock), WithFailureThreshold(3))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for range 2 {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+	}
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	snap := cb.Snapshot()
+	if snap.State != Closed {
+		t.Errorf("Expected closed state, got %v", snap.State)
+	}
+	if snap.Failures != 2 || snap.Successes != 1 {
+		t.Errorf("Expected 2 failures and 1 success, got %d and %d", snap.Failures, snap.Successes)
+	}
+	if !snap.HalfOpenAt.IsZero() {
+		t.Errorf("HalfOpenAt should be zero while closed, got %v", snap.HalfOpenAt)
+	}
+	if snap.Settings.FailureThreshold != 3 {
+		t.Errorf("Expected failure threshold 3 in settings, got %d", snap.Settings.FailureThreshold)
+	}
+	if snap.Settings.CooldownTimer != 120*time.Second {
+		t.Errorf("Expected default cooldown in settings, got %v", snap.Settings.CooldownTimer)
+	}
+}
+
+func TestSnapshotOpenState(t *testing.T) {
+	now := time.Now()
+	fakeClock := &FakeClock{now: now}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithCooldownTimer(30*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	snap := cb.Snapshot()
+	if snap.State != Open {
+		t.Fatalf("Expected open state, got %v", snap.State)
+	}
+	if !snap.HalfOpenAt.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("Expected half-open at %v, got %v", now.Add(30*time.Second), snap.HalfOpenAt)
+	}
+
+	fakeClock.Advance(10 * time.Second)
+	if got := snap.RetryAfter(fakeClock.Now()); got != 20*time.Second {
+		t.Errorf("Expected 20s retry-after, got %v", got)
+	}
+}
+
+func TestSnapshotProbesInUse(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	var during Snapshot
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		during = cb.Snapshot()
+		return nil
+	})
+
+	if during.State != HalfOpen {
+		t.Errorf("Expected half-open state during probe, got %v", during.State)
+	}
+	if during.ProbesInUse != 1 {
+		t.Errorf("Expected 1 probe in use during probe, got %d", during.ProbesInUse)
+	}
+	if after := cb.Snapshot(); after.ProbesInUse != 0 {
+		t.Errorf("Expected probe to be released, got %d in use", after.ProbesInUse)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{Closed, "closed"},
+		{Open, "open"},
+		{HalfOpen, "half-open"},
+		{State(42), "State(42)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("State(%d).String() = %q, want %q", int64(tt.state), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAllowRecordsReportedOutcome(t *testing.T) {
+	cb, err := New(WithFailureThreshold(2))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	for _, report := range []func(Ticket){
+		func(t Ticket) { t.Success() },
+		func(t Ticket) { t.Ignore() },
+		func(t Ticket) { t.Failure(errors.New("stream reset")) },
+	} {
+		ticket, err := cb.Allow(context.Background())
+		if err != nil {
+			t.Fatalf("Expected admission, got %v", err)
+		}
+		report(ticket)
+	}
+
+	snap := cb.Snapshot()
+	if snap.Successes != 1 || snap.Failures != 1 {
+		t.Errorf("Expected 1 success and 1 failure, got %d and %d", snap.Successes, snap.Failures)
+	}
+
+	ticket, _ := cb.Allow(context.Background())
+	ticket.Failure(errors.New("stream reset"))
+	if cb.State() != Open {
+		t.Fatalf("Expected second failure to open the circuit, got %v", cb.State())
+	}
+
+	ticket, err = cb.Allow(context.Background())
+	if ticket != nil || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected rejection without a ticket, got %v and %v", ticket, err)
+	}
+}
+
+func TestAllowWithDoneContext(t *testing.T) {
+	cb, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ticket, err := cb.Allow(ctx); ticket != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled without a ticket, got %v and %v", ticket, err)
+	}
+}
+
+func TestTicketIsIdempotent(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithSuccessToClose(2))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	ticket, err := cb.Allow(context.Background())
+	if err != nil {
+		t.Fatalf("Expected probe admission, got %v", err)
+	}
+
+	// Outcomes racing in from several goroutines settle the probe exactly once
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticket.Success()
+			ticket.Failure(errors.New("late failure"))
+			ticket.Ignore()
+		}()
+	}
+	wg.Wait()
+
+	snap := cb.Snapshot()
+	if snap.ProbesInUse != 0 {
+		t.Errorf("Probe slot should be released once, got %d in use", snap.ProbesInUse)
+	}
+	if snap.State != HalfOpen || snap.Successes+snap.Failures != 1 {
+		t.Errorf("Expected exactly one recorded outcome while half-open, got %v with %d successes and %d failures",
+			snap.State, snap.Successes, snap.Failures)
+	}
+}
+
+func TestTicketFromAnotherGoroutine(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithSuccessToClose(1))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(121 * time.Second)
+
+	ticket, err := cb.Allow(context.Background())
+	if err != nil {
+		t.Fatalf("Expected probe admission, got %v", err)
+	}
+
+	// A second caller is rejected while the probe is outstanding
+	if _, err := cb.Allow(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected probes exhausted, got %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticket.Success()
+	}()
+	<-done
+
+	if cb.State() != Closed {
+		t.Errorf("Successful probe reported asynchronously should close the circuit, got %v", cb.State())
+	}
+}
+
+func TestTicketDetectsSlowCalls(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(WithClock(fakeClock), WithSlowCallThreshold(time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ticket, err := cb.Allow(context.Background())
+	if err != nil {
+		t.Fatalf("Expected admission, got %v", err)
+	}
+	fakeClock.Advance(3 * time.Second)
+	ticket.Success()
+
+	if snap := cb.Snapshot(); snap.SlowCalls != 1 || snap.Successes != 1 {
+		t.Errorf("Expected 1 slow success, got %d slow and %d successes", snap.SlowCalls, snap.Successes)
+	}
+}
@@ -0,0 +1,110 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitForDeadline(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCallTimeoutCountsAsFailure(t *testing.T) {
+	cb, err := NewZeroTolerance(
+		WithCallTimeout(10*time.Millisecond),
+		WithIgnoredErrors(context.DeadlineExceeded),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	err = cb.Try(context.Background(), waitForDeadline)
+	if !errors.Is(err, ErrCallTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error wrapping ErrCallTimeout and the call's error, got %v", err)
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != Open {
+		t.Errorf("Timeout should open a zero-tolerance breaker even when deadlines are ignored, got %v", snap.State)
+	}
+	if snap.TimedOutCalls != 1 {
+		t.Errorf("Expected 1 timed-out call, got %d", snap.TimedOutCalls)
+	}
+	if snap.Settings.CallTimeout != 10*time.Millisecond {
+		t.Errorf("Expected call timeout in settings, got %v", snap.Settings.CallTimeout)
+	}
+}
+
+func TestCallerDeadlineIsNotATimeout(t *testing.T) {
+	cb, err := NewZeroTolerance(
+		WithCallTimeout(time.Minute),
+		WithIgnoredErrors(context.DeadlineExceeded),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = cb.Try(ctx, waitForDeadline)
+	if errors.Is(err, ErrCallTimeout) {
+		t.Errorf("Caller's deadline should not be reported as a call timeout, got %v", err)
+	}
+
+	snap := cb.Snapshot()
+	if snap.State != Closed || snap.TimedOutCalls != 0 {
+		t.Errorf("Caller's deadline should be classified normally, got %v with %d timeouts", snap.State, snap.TimedOutCalls)
+	}
+}
+
+func TestCallTimeoutReopensFromHalfOpen(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := NewZeroTolerance(WithClock(fakeClock), WithCallTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Try(context.Background(), waitForDeadline)
+	fakeClock.Advance(121 * time.Second)
+
+	cb.Try(context.Background(), waitForDeadline)
+
+	snap := cb.Snapshot()
+	if snap.State != Open || snap.ConsecutiveOpens != 2 {
+		t.Errorf("Timed-out probe should reopen the circuit, got %v after %d opens", snap.State, snap.ConsecutiveOpens)
+	}
+	if snap.ProbesInUse != 0 {
+		t.Errorf("Timed-out probe should release its slot, got %d in use", snap.ProbesInUse)
+	}
+}
+
+func TestCallTimeoutLeavesFastCallsAlone(t *testing.T) {
+	cb, err := NewZeroTolerance(WithCallTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	err = cb.Try(context.Background(), func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the call's context to carry a deadline")
+		}
+		return nil
+	})
+	if err != nil || cb.State() != Closed {
+		t.Errorf("Expected fast call to succeed, got %v in %v", err, cb.State())
+	}
+}
+
+func TestCallTimeoutValidation(t *testing.T) {
+	if _, err := New(WithCallTimeout(0)); err == nil {
+		t.Error("Expected error for zero call timeout")
+	}
+}
@@ -0,0 +1,216 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport records idle-connection cleanups on top of a real transport.
+type countingTransport struct {
+	http.RoundTripper
+	idleClosed atomic.Int32
+}
+
+func (c *countingTransport) CloseIdleConnections() {
+	c.idleClosed.Add(1)
+}
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTransportPerHostBreakers(t *testing.T) {
+	var failingHits atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failingHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("down"))
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	base := &countingTransport{RoundTripper: http.DefaultTransport}
+	transport, err := NewTransport(base, WithBreakerOptions(WithFailureThreshold(2)))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(failing.URL)
+		if err != nil {
+			t.Fatalf("Expected the 503 response to reach the caller, got %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || string(body) != "down" {
+			t.Errorf("Expected unchanged 503 response, got %d %q", resp.StatusCode, body)
+		}
+	}
+
+	_, err = client.Get(failing.URL)
+	var openErr *OpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("Expected *OpenError once the host's breaker opened, got %v", err)
+	}
+	if openErr.Name != strings.TrimPrefix(failing.URL, "http://") {
+		t.Errorf("Expected breaker named after the host, got %q", openErr.Name)
+	}
+	if hits := failingHits.Load(); hits != 2 {
+		t.Errorf("Rejected request should not reach the server, got %d hits", hits)
+	}
+
+	resp, err := client.Get(healthy.URL)
+	if err != nil {
+		t.Fatalf("Other hosts should be unaffected, got %v", err)
+	}
+	resp.Body.Close()
+
+	if names := transport.Breakers().Names(); len(names) != 2 {
+		t.Errorf("Expected one breaker per host, got %v", names)
+	}
+	if n := base.idleClosed.Load(); n != 0 {
+		t.Errorf("Round trippers other than *http.Transport should keep their connections, closed %d times", n)
+	}
+}
+
+func TestTransportClosesIdleConnectionsOfOpenedHost(t *testing.T) {
+	// countClosed starts a server that counts the connections it saw closed.
+	countClosed := func(closed *atomic.Int32) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed {
+				closed.Add(1)
+			}
+		}
+		server.Start()
+		return server
+	}
+	var failingClosed, healthyClosed atomic.Int32
+	failing := countClosed(&failingClosed)
+	defer failing.Close()
+	healthy := countClosed(&healthyClosed)
+	defer healthy.Close()
+
+	transport, err := NewTransport(&http.Transport{})
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	// Leave an idle connection to each host in its pool
+	for _, url := range []string{failing.URL, healthy.URL} {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Expected a response, got %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	cb, err := transport.Breakers().Get(strings.TrimPrefix(failing.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to get breaker: %v", err)
+	}
+	cb.ForceOpen()
+
+	// Listeners run on the breaker's goroutine, so allow the cleanup to land
+	deadline := time.Now().Add(time.Second)
+	for failingClosed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if failingClosed.Load() == 0 {
+		t.Error("Expected the opened host's idle connection to be closed")
+	}
+	if n := healthyClosed.Load(); n != 0 {
+		t.Errorf("Other hosts should keep their idle connections, %d closed", n)
+	}
+}
+
+func TestTransportClassification(t *testing.T) {
+	statuses := map[string]int{"/ok": 200, "/redirect": 304, "/missing": 404, "/slow-down": 429, "/timeout": 408}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[r.URL.Path])
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(nil,
+		WithKeyFunc(func(r *http.Request) string { return r.URL.Path }),
+		WithBreakerOptions(WithFailureThreshold(1)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	for path, status := range statuses {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", path, err)
+		}
+		resp.Body.Close()
+
+		cb, _ := transport.Breakers().Get(path)
+		wantOpen := status == 429 || status == 408
+		if got := cb.State() == Open; got != wantOpen {
+			t.Errorf("%s (%d): expected open=%v, got %v", path, status, wantOpen, cb.State())
+		}
+	}
+}
+
+func TestTransportNetworkErrorOpensCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	transport, err := NewTransport(nil, WithBreakerOptions(WithFailureThreshold(1)))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	if _, err := client.Get(url); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a network error, got %v", err)
+	}
+
+	body := &trackedBody{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequest("POST", url, body)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after a network error, got %v", err)
+	}
+	if !body.closed {
+		t.Error("Rejected request body should be closed")
+	}
+}
+
+func TestTransportValidation(t *testing.T) {
+	if _, err := NewTransport(nil, WithKeyFunc(nil)); err == nil {
+		t.Error("Expected error for nil key function")
+	}
+	if _, err := NewTransport(nil, WithBreakerOptions(WithFailureThreshold(0))); err == nil {
+		t.Error("Expected error for invalid breaker options")
+	}
+}
@@ -0,0 +1,185 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlidingCountWindowEvictsOldFailures(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(3),
+		WithSlidingCountWindow(5),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	failure := func(ctx context.Context) error { return errors.New("simulated failure") }
+	success := func(ctx context.Context) error { return nil }
+
+	// The first failure is evicted by the time the next two arrive.
+	for i, fn := range []func(context.Context) error{failure, success, success, success, success, failure, failure} {
+		cb.Execute(context.Background(), fn)
+		if cb.State() != Closed {
+			t.Fatalf("Call %d: breaker should stay closed with at most 2 failures in the last 5 calls", i+1)
+		}
+	}
+
+	snap := cb.Snapshot()
+	if snap.Failures != 2 || snap.Successes != 3 {
+		t.Errorf("Expected 2 failures and 3 successes in window, got %d and %d", snap.Failures, snap.Successes)
+	}
+
+	cb.Execute(context.Background(), failure)
+	if cb.State() != Open {
+		t.Errorf("Breaker should open with 3 failures in the last 5 calls, got %v", cb.State())
+	}
+}
+
+func TestSlidingCountWindowDoesNotAgeOut(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Now()}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(2),
+		WithSlidingCountWindow(10),
+		WithWindowSize(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	fakeClock.Advance(time.Hour)
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	if cb.State() != Open {
+		t.Errorf("Failures should not age out of a count window, got %v", cb.State())
+	}
+}
+
+func TestTimeWindowBucketsAgeOutIndividually(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Unix(1700000000, 0)}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(3),
+		WithWindowSize(10*time.Second),
+		WithWindowBuckets(10),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	fail := func() {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			return errors.New("simulated failure")
+		})
+	}
+
+	fail() // t=0s
+	fakeClock.Advance(5 * time.Second)
+	fail() // t=5s
+	fakeClock.Advance(5 * time.Second)
+	if got := cb.Snapshot().Failures; got != 1 {
+		t.Fatalf("Expected only the failure from t=5s in the window at t=10s, got %d", got)
+	}
+
+	fail() // t=10s
+	if cb.State() != Closed {
+		t.Fatalf("Breaker should stay closed with 2 failures in the window, got %v", cb.State())
+	}
+
+	fakeClock.Advance(4 * time.Second)
+	fail() // t=14s, window still holds t=5s and t=10s
+	if cb.State() != Open {
+		t.Errorf("Breaker should open with 3 failures in the last 10s, got %v", cb.State())
+	}
+}
+
+func TestTimeWindowForgetsQuietPeriods(t *testing.T) {
+	fakeClock := &FakeClock{now: time.Unix(1700000000, 0)}
+	cb, err := New(
+		WithClock(fakeClock),
+		WithFailureThreshold(2),
+		WithWindowSize(10*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+	// A multiple of the window lands on the same bucket index.
+	fakeClock.Advance(30 * time.Second)
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("simulated failure")
+	})
+
+	if cb.State() != Closed {
+		t.Errorf("Failure from three windows ago should not count, got %v", cb.State())
+	}
+}
+
+func TestSlidingCountWindowConcurrentRecording(t *testing.T) {
+	w := newCountWindow(64)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				w.record(newSample(g%2 == 0))
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failures int64
+	for i := range w.slots {
+		if sample(w.slots[i].Load())&sampleFailure != 0 {
+			failures++
+		}
+	}
+
+	counts := w.counts()
+	if counts.calls != 64 {
+		t.Errorf("Expected a full window of 64 calls, got %d", counts.calls)
+	}
+	if counts.failures != failures {
+		t.Errorf("Running failure count %d does not match window contents %d", counts.failures, failures)
+	}
+
+	w.reset()
+	if counts := w.counts(); counts.calls != 0 || counts.failures != 0 {
+		t.Errorf("Expected empty window after reset, got %+v", counts)
+	}
+}
+
+func TestWindowValidation(t *testing.T) {
+	if _, err := New(WithSlidingCountWindow(0)); err == nil {
+		t.Error("Expected error for empty sliding window")
+	}
+	if _, err := New(WithFailureThreshold(5), WithSlidingCountWindow(4)); err == nil {
+		t.Error("Expected error when the failure threshold exceeds the window size")
+	}
+	if _, err := New(WithWindowBuckets(0)); err == nil {
+		t.Error("Expected error for zero window buckets")
+	}
+	if _, err := New(WithWindowSize(5*time.Nanosecond), WithWindowBuckets(10)); err == nil {
+		t.Error("Expected error when buckets would be shorter than a nanosecond")
+	}
+}
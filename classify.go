@@ -0,0 +1,43 @@
+package circuitbreaker
+
+import "errors"
+
+// outcome is how the breaker accounts for a completed call.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+// decided carries an outcome chosen by the caller of run, such as an HTTP classifier,
+// in place of the configured classification. run unwraps it and returns err.
+type decided struct {
+	o   outcome
+	err error
+}
+
+func (d *decided) Error() string {
+	if d.err == nil {
+		return "<nil>"
+	}
+	return d.err.Error()
+}
+
+// classify decides how err is counted. Ignored errors take precedence over the
+// failure predicate, and a nil error is always a success.
+func (c config) classify(err error) outcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	for _, target := range c.ignoredErrors {
+		if errors.Is(err, target) {
+			return outcomeIgnored
+		}
+	}
+	if c.isFailure != nil && !c.isFailure(err) {
+		return outcomeSuccess
+	}
+	return outcomeFailure
+}
@@ -0,0 +1,76 @@
+package circuitbreaker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// CooldownStrategy decides how long the breaker stays open before admitting probes.
+type CooldownStrategy interface {
+	// Cooldown returns the open duration for the given consecutive opening, where
+	// opens is 1 the first time the breaker opens after being closed and grows with
+	// every failed half-open attempt. prev is the cooldown of the previous opening,
+	// or 0 for the first one.
+	Cooldown(opens int64, prev time.Duration) time.Duration
+}
+
+// CooldownFunc adapts a function to the CooldownStrategy interface.
+type CooldownFunc func(opens int64, prev time.Duration) time.Duration
+
+// Cooldown calls f(opens, prev).
+func (f CooldownFunc) Cooldown(opens int64, prev time.Duration) time.Duration {
+	return f(opens, prev)
+}
+
+// ConstantCooldown keeps the breaker open for d after every opening.
+// This is the default strategy, using the duration set by WithCooldownTimer.
+func ConstantCooldown(d time.Duration) CooldownStrategy {
+	return CooldownFunc(func(int64, time.Duration) time.Duration {
+		return d
+	})
+}
+
+// ExponentialCooldown doubles the cooldown after each failed half-open attempt,
+// starting at base and never exceeding maximum.
+func ExponentialCooldown(base, maximum time.Duration) CooldownStrategy {
+	return CooldownFunc(func(opens int64, _ time.Duration) time.Duration {
+		return exponential(base, maximum, opens)
+	})
+}
+
+// FullJitterCooldown picks a cooldown uniformly at random between zero and the
+// ExponentialCooldown value, so a fleet of instances does not reopen in lockstep.
+func FullJitterCooldown(base, maximum time.Duration) CooldownStrategy {
+	return CooldownFunc(func(opens int64, _ time.Duration) time.Duration {
+		return randomBetween(0, exponential(base, maximum, opens))
+	})
+}
+
+// DecorrelatedJitterCooldown picks a cooldown at random between base and three
+// times the previous cooldown, capped at maximum. It spreads reopen times like
+// FullJitterCooldown while never dropping below base.
+func DecorrelatedJitterCooldown(base, maximum time.Duration) CooldownStrategy {
+	return CooldownFunc(func(_ int64, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+		return min(maximum, randomBetween(base, 3*prev))
+	})
+}
+
+// exponential returns base*2^(n-1), capped at maximum.
+func exponential(base, maximum time.Duration, n int64) time.Duration {
+	d := base
+	for i := int64(1); i < n && d < maximum; i++ {
+		d *= 2
+	}
+	return min(d, maximum)
+}
+
+// randomBetween returns a random duration in [lo, hi].
+func randomBetween(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(rand.Int63n(int64(hi-lo)+1)) // #nosec G404
+}
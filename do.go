@@ -0,0 +1,52 @@
+package circuitbreaker
+
+import "context"
+
+// Do runs fn through cb once and returns its typed result. A rejected call returns the
+// zero value and an *OpenError matching ErrCircuitOpen, as with Try.
+//
+//	user, err := circuitbreaker.Do(ctx, cb, func(ctx context.Context) (*User, error) {
+//	    return client.GetUser(ctx, id)
+//	})
+func Do[T any](ctx context.Context, cb CircuitBreaker, fn func(context.Context) (T, error)) (T, error) {
+	var result T
+	err := cb.Try(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+// DoBlocking runs fn through cb like ExecuteBlocking, waiting for the breaker to admit
+// the call, and returns its typed result. If ctx is done first it returns the zero value
+// and ctx.Err().
+func DoBlocking[T any](ctx context.Context, cb CircuitBreaker, fn func(context.Context) (T, error)) (T, error) {
+	var result T
+	err := cb.ExecuteBlocking(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+// DoGRPCBlocking is the typed form of ExecuteGRPCBlocking: it retries fn until it
+// succeeds or ctx is done, without a type assertion at the call site.
+//
+//	resp, err := circuitbreaker.DoGRPCBlocking(ctx, cb, func(ctx context.Context) (*pb.ListItemsResponse, error) {
+//	    return client.ListItems(ctx, req)
+//	})
+func DoGRPCBlocking[T any](ctx context.Context, cb CircuitBreaker, fn func(context.Context) (T, error)) (T, error) {
+	var result T
+	_, err := cb.ExecuteGRPCBlocking(ctx, func(ctx context.Context) (interface{}, error) {
+		var err error
+		result, err = fn(ctx)
+		return nil, err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
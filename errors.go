@@ -0,0 +1,93 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCircuitOpen is the sentinel matched by every rejection error returned by a circuit breaker.
+// Use errors.Is(err, ErrCircuitOpen) to detect rejected calls, or errors.As with *OpenError
+// to inspect the details.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrSlowCall is reported as the cause when a call that succeeded too slowly opens the circuit.
+var ErrSlowCall = errors.New("call exceeded slow call threshold")
+
+// ErrCallTimeout is wrapped around the error of a call that did not finish within the
+// duration set by WithCallTimeout.
+var ErrCallTimeout = errors.New("call timed out")
+
+// ErrRetriesExhausted is wrapped, together with the last attempt's error, around the
+// error a blocking method returns once its RetryPolicy allows no further attempts.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// RejectReason describes why a circuit breaker rejected a call.
+type RejectReason int
+
+// Rejection reasons.
+const (
+	// ReasonOpen means the breaker is open and its cooldown has not elapsed.
+	ReasonOpen RejectReason = iota
+	// ReasonProbesExhausted means the breaker is half-open and every probe slot is in use.
+	ReasonProbesExhausted
+	// ReasonForcedOpen means an operator forced the breaker open.
+	ReasonForcedOpen
+)
+
+// String returns a short description of the reason.
+func (r RejectReason) String() string {
+	switch r {
+	case ReasonOpen:
+		return "open"
+	case ReasonProbesExhausted:
+		return "half-open probes exhausted"
+	case ReasonForcedOpen:
+		return "forced open"
+	default:
+		return fmt.Sprintf("RejectReason(%d)", int(r))
+	}
+}
+
+// OpenError is returned when a circuit breaker rejects a call without running it.
+type OpenError struct {
+	// Name is the name of the breaker that rejected the call, if it has one.
+	Name string
+	// State is the breaker state at the time of rejection.
+	State State
+	// RetryAfter is how long the caller should wait before trying again.
+	RetryAfter time.Duration
+	// Reason tells whether the breaker was open or out of half-open probes.
+	Reason RejectReason
+}
+
+func (e *OpenError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s: %v (%v, retry after %v)", e.Name, ErrCircuitOpen, e.Reason, e.RetryAfter)
+	}
+	return fmt.Sprintf("%v (%v, retry after %v)", ErrCircuitOpen, e.Reason, e.RetryAfter)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// PanicError is returned in place of a panic raised by a call when the breaker is
+// configured with WithPanicPolicy(PanicReturnError).
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is and errors.As see it.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,178 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies the kind of Event published by a circuit breaker.
+type EventType int
+
+// Event types.
+const (
+	// EventStateChange is published after the breaker moves From one state To another.
+	EventStateChange EventType = iota
+	// EventRejected is published when a call is rejected; Err holds the *OpenError.
+	EventRejected
+	// EventProbeStart is published when a half-open probe is admitted.
+	EventProbeStart
+	// EventProbeFinish is published when a half-open probe completes; Err holds its error.
+	EventProbeFinish
+)
+
+// String returns a short name for the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventStateChange:
+		return "state-change"
+	case EventRejected:
+		return "rejected"
+	case EventProbeStart:
+		return "probe-start"
+	case EventProbeFinish:
+		return "probe-finish"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event describes something that happened to a circuit breaker.
+type Event struct {
+	Type EventType
+	// Name is the name the breaker was configured with, if any.
+	Name string
+	Time time.Time
+	// From and To are the states involved. For events other than EventStateChange
+	// both hold the state the breaker was in.
+	From State
+	To   State
+	// Err is the error that caused the event, if any.
+	Err error
+}
+
+// StateChangeFunc is called after a circuit breaker changes state.
+// cause is the error that triggered the transition, or nil.
+type StateChangeFunc func(from, to State, cause error)
+
+// eventHub fans events out to subscribers and state-change listeners without
+// blocking the caller. Events that do not fit in a bounded buffer are dropped.
+type eventHub struct {
+	listeners []StateChangeFunc
+	queue     chan Event
+	buffer    int
+
+	mu     sync.RWMutex
+	subs   map[chan Event]struct{}
+	closed bool
+
+	subscribers atomic.Int64
+	dropped     atomic.Int64
+}
+
+func newEventHub(listeners []StateChangeFunc, buffer int) *eventHub {
+	h := &eventHub{
+		listeners: listeners,
+		buffer:    buffer,
+		subs:      make(map[chan Event]struct{}),
+	}
+	if len(listeners) > 0 {
+		h.queue = make(chan Event, buffer)
+	}
+	return h
+}
+
+// enabled reports whether anyone is interested in events, so callers can skip
+// building them on the hot path.
+func (h *eventHub) enabled() bool {
+	return h.queue != nil || h.subscribers.Load() > 0
+}
+
+func (h *eventHub) publish(ev Event) {
+	if ev.Type == EventStateChange && h.queue != nil {
+		select {
+		case h.queue <- ev:
+		default:
+			h.dropped.Add(1)
+		}
+	}
+
+	if h.subscribers.Load() == 0 {
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			h.dropped.Add(1)
+		}
+	}
+}
+
+// dispatch runs the state-change listeners for ev. It is called from the
+// breaker's background goroutine.
+func (h *eventHub) dispatch(ev Event) {
+	for _, fn := range h.listeners {
+		fn(ev.From, ev.To, ev.Err)
+	}
+}
+
+func (h *eventHub) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, h.buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+	h.subscribers.Add(1)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subs[ch]; ok {
+				delete(h.subs, ch)
+				h.subscribers.Add(-1)
+				close(ch)
+			}
+		})
+	}
+}
+
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+	h.subscribers.Store(0)
+}
+
+// Subscribe returns a channel of events published by the circuit breaker and a
+// function that cancels the subscription and closes the channel.
+//
+// Delivery never blocks the breaker: when the channel buffer is full, events are
+// dropped. The channel is closed when the breaker is closed.
+func (cb *circuitBreaker) Subscribe() (<-chan Event, func()) {
+	return cb.events.subscribe()
+}
+
+func (cb *circuitBreaker) publish(typ EventType, from, to State, err error) {
+	cb.events.publish(Event{
+		Type: typ,
+		Name: cb.config.name,
+		Time: cb.clock.Now(),
+		From: from,
+		To:   to,
+		Err:  err,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver for demonstration
@@ -42,36 +41,27 @@ func NewDB(db *sql.DB) (*DB, error) {
 
 // Query executes a query that returns multiple rows with circuit breaker protection
 func (d *DB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	var rows *sql.Rows
-	var queryErr error
-
-	timer, err := d.breaker.Execute(ctx, func(ctx context.Context) error {
-		rows, queryErr = d.db.QueryContext(ctx, query, args...)
-		return queryErr
+	rows, err := circuitbreaker.Do(ctx, d.breaker, func(ctx context.Context) (*sql.Rows, error) {
+		return d.db.QueryContext(ctx, query, args...)
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("circuit breaker open")
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return nil, err
 	}
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 
-	return rows, queryErr
+	return rows, nil
 }
 
 // QueryRow executes a query that returns a single row with circuit breaker protection
 func (d *DB) QueryRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
-	var row *sql.Row
-
-	timer, err := d.breaker.Execute(ctx, func(ctx context.Context) error {
-		row = d.db.QueryRowContext(ctx, query, args...)
-		return nil // QueryRow doesn't return an error, defer error checking to Scan()
+	row, err := circuitbreaker.Do(ctx, d.breaker, func(ctx context.Context) (*sql.Row, error) {
+		// QueryRow doesn't return an error, defer error checking to Scan()
+		return d.db.QueryRowContext(ctx, query, args...), nil
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("circuit breaker open")
-	}
 	if err != nil {
 		return nil, err
 	}
@@ -81,22 +71,18 @@ func (d *DB) QueryRow(ctx context.Context, query string, args ...interface{}) (*
 
 // Exec executes a query without returning rows (INSERT, UPDATE, DELETE)
 func (d *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	var result sql.Result
-	var execErr error
-
-	timer, err := d.breaker.Execute(ctx, func(ctx context.Context) error {
-		result, execErr = d.db.ExecContext(ctx, query, args...)
-		return execErr
+	result, err := circuitbreaker.Do(ctx, d.breaker, func(ctx context.Context) (sql.Result, error) {
+		return d.db.ExecContext(ctx, query, args...)
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("circuit breaker open")
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return nil, err
 	}
 	if err != nil {
 		return nil, fmt.Errorf("exec failed: %w", err)
 	}
 
-	return result, execErr
+	return result, nil
 }
 
 // Close closes the underlying database connection
@@ -223,7 +209,7 @@ func main() {
 	// Next request should be blocked
 	_, err = db.Query(ctx, "SELECT * FROM users")
 	if err != nil {
-		if strings.Contains(err.Error(), "circuit breaker is open") {
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			fmt.Println("Circuit breaker successfully blocked the request!")
 			fmt.Println("Database is protected from being hammered during failures")
 		} else {
@@ -32,7 +32,7 @@ func (c *MockGRPCClient) ListItems(ctx context.Context, req *ListItemsRequest) (
 	}, nil
 }
 
-// Example: Using ExecuteGRPCBlocking for clean gRPC calls
+// Example: Using DoGRPCBlocking for clean, typed gRPC calls
 func main() {
 	// Create circuit breaker
 	cb, err := circuitbreaker.NewZeroTolerance(
@@ -52,7 +52,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	resp, err := cb.ExecuteGRPCBlocking(ctx, func(ctx context.Context) (interface{}, error) {
+	resp, err := circuitbreaker.DoGRPCBlocking(ctx, cb, func(ctx context.Context) (*ListItemsResponse, error) {
 		return client.ListItems(ctx, req)
 	})
 
@@ -60,9 +60,8 @@ func main() {
 		log.Fatalf("Circuit breaker could not complete request: %v", err)
 	}
 
-	// Type assert the response
-	typedResp := resp.(*ListItemsResponse)
-	fmt.Printf("Success! Got %d items (total: %d)\n", len(typedResp.Items), typedResp.Total)
+	// The response is already typed - no assertion needed
+	fmt.Printf("Success! Got %d items (total: %d)\n", len(resp.Items), resp.Total)
 	fmt.Println()
 
 	fmt.Println("=== Comparison: Old vs New Pattern ===")
@@ -101,7 +100,7 @@ func main() {
 	fmt.Println()
 	fmt.Println("NEW PATTERN (3 lines):")
 	fmt.Println(`
-	resp, err := cb.ExecuteGRPCBlocking(ctx, func(ctx context.Context) (interface{}, error) {
+	resp, err := circuitbreaker.DoGRPCBlocking(ctx, cb, func(ctx context.Context) (*pb.Response, error) {
 		return client.SomeMethod(ctx, req)
 	})
 	if err != nil {
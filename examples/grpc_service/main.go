@@ -12,12 +12,17 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// CircuitBreakerInterceptor creates a gRPC unary client interceptor with circuit breaker
+// CircuitBreakerInterceptor creates a gRPC unary client interceptor with circuit breaker.
+// The github.com/michael-jaquier/circuitbreaker/grpc module ships production-ready
+// versions of these interceptors; this example shows how they work.
 // This interceptor wraps all gRPC client calls with circuit breaker protection
 // It opens the circuit on:
 // - Network errors
 // - Server errors (Unavailable, Internal, etc.)
 // - Timeout errors
+//
+// The breaker should be created with WithIsFailure(shouldOpenCircuit) so that client
+// errors reach the caller unchanged without counting against the circuit.
 func CircuitBreakerInterceptor(cb circuitbreaker.CircuitBreaker) grpc.UnaryClientInterceptor {
 	return func(
 		ctx context.Context,
@@ -27,33 +32,17 @@ func CircuitBreakerInterceptor(cb circuitbreaker.CircuitBreaker) grpc.UnaryClien
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) error {
-		var opErr error
-
-		timer, err := cb.Execute(ctx, func(ctx context.Context) error {
-			// Invoke the actual RPC
-			opErr = invoker(ctx, method, req, reply, cc, opts...)
-
-			// Determine if error should open the circuit
-			if opErr != nil && shouldOpenCircuit(opErr) {
-				return opErr
-			}
-			// Client errors don't open circuit, return nil to report success
-			return nil
+		err := cb.Try(ctx, func(ctx context.Context) error {
+			// Invoke the actual RPC; the breaker decides whether its error counts
+			return invoker(ctx, method, req, reply, cc, opts...)
 		})
 
-		if timer != nil {
-			// Circuit breaker is open, calculate wait time from timer
-			// The timer will fire when the cooldown period expires
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			return status.Errorf(codes.Unavailable,
 				"circuit breaker open for %s", method)
 		}
 
-		if err != nil {
-			// Execute returned an error (circuit breaker failure)
-			return opErr
-		}
-
-		return opErr
+		return err
 	}
 }
 
@@ -105,32 +94,19 @@ func StreamCircuitBreakerInterceptor(cb circuitbreaker.CircuitBreaker) grpc.Stre
 		opts ...grpc.CallOption,
 	) (grpc.ClientStream, error) {
 		var stream grpc.ClientStream
-		var opErr error
-
-		timer, err := cb.Execute(ctx, func(ctx context.Context) error {
-			stream, opErr = streamer(ctx, desc, cc, method, opts...)
 
-			// Determine if error should open the circuit
-			if opErr != nil && shouldOpenCircuit(opErr) {
-				return opErr
-			}
-			// Client errors don't open circuit, return nil to report success
-			return nil
+		err := cb.Try(ctx, func(ctx context.Context) error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
 		})
 
-		if timer != nil {
-			// Circuit breaker is open, calculate wait time from timer
-			// The timer will fire when the cooldown period expires
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			return nil, status.Errorf(codes.Unavailable,
 				"circuit breaker open for stream %s", method)
 		}
 
-		if err != nil {
-			// Execute returned an error (circuit breaker failure)
-			return nil, opErr
-		}
-
-		return stream, opErr
+		return stream, err
 	}
 }
 
@@ -141,6 +117,8 @@ func NewGRPCClientConn(target string, opts ...grpc.DialOption) (*grpc.ClientConn
 		circuitbreaker.WithCooldownTimer(30*time.Second),
 		circuitbreaker.WithSuccessToClose(5),
 		circuitbreaker.WithWindowSize(120*time.Second),
+		// Only server-side failures count; client errors are returned as-is
+		circuitbreaker.WithIsFailure(shouldOpenCircuit),
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create circuit breaker: %w", err)
@@ -332,32 +310,27 @@ if err != nil {
 }
 
 func (c *ServiceClient) ListItems(ctx context.Context) error {
-    var opErr error
-    timer, err := c.listBreaker.Execute(ctx, func(ctx context.Context) error {
-        // Make gRPC call
-        opErr = c.client.List(ctx, req)
-        if opErr != nil && shouldOpenCircuit(opErr) {
-            return opErr
-        }
-        return nil
+    // listBreaker was created with WithIsFailure(shouldOpenCircuit)
+    err := c.listBreaker.Try(ctx, func(ctx context.Context) error {
+        return c.client.List(ctx, req)
     })
-    if timer != nil {
+    if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
         return handleCircuitOpen()
     }
-    return opErr
+    return err
 }`)
 	fmt.Println("```")
 
 	fmt.Println("Integration Pattern 3: Fallback strategies")
 	fmt.Println("```go")
-	fmt.Println(`resp, err := client.GetUser(ctx, req)
-if err != nil {
-    if status.Code(err) == codes.Unavailable {
-        // Circuit is open, use cache
+	fmt.Println(`// The fallback runs when the circuit is open or the call fails
+return circuitbreaker.DoWithFallback(ctx, userBreaker,
+    func(ctx context.Context) (*pb.User, error) {
+        return client.GetUser(ctx, req)
+    },
+    func(ctx context.Context, cause error) (*pb.User, error) {
         return getCachedUser(req.UserId)
-    }
-    return nil, err
-}`)
+    })`)
 	fmt.Println("```")
 
 	fmt.Println("=== Key Takeaways ===")
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -74,7 +75,9 @@ func (a *APIClient) Get(ctx context.Context, path string) ([]byte, error) {
 	})
 
 	if timer != nil {
-		return nil, fmt.Errorf("circuit breaker is open, service unavailable")
+		// Not waiting for the circuit to recover, so release the timer
+		timer.Stop()
+		return nil, circuitbreaker.ErrCircuitOpen
 	}
 	if execErr != nil {
 		return nil, fmt.Errorf("request failed: %w", execErr)
@@ -110,7 +113,8 @@ func (a *APIClient) Post(ctx context.Context, path string, payload interface{})
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := a.breaker.Execute(ctx, func(ctx context.Context) error {
+	// Try reports a rejection as an error matching circuitbreaker.ErrCircuitOpen
+	execErr := a.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = a.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -123,8 +127,8 @@ func (a *APIClient) Post(ctx context.Context, path string, payload interface{})
 		return nil
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("circuit breaker is open")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return nil, execErr
 	}
 	if execErr != nil {
 		return nil, fmt.Errorf("request failed: %w", execErr)
@@ -254,7 +258,7 @@ func main() {
 	_, err = badClient.Get(ctx, "/test")
 	if err != nil {
 		fmt.Printf("Second request error: %v\n", err)
-		if strings.Contains(err.Error(), "circuit breaker is open") {
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			fmt.Println("Circuit breaker successfully blocked the request!")
 			fmt.Println()
 		}
@@ -351,6 +355,8 @@ func main() {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
+		// POST is only retried when the server can deduplicate it
+		req.Header.Set("Idempotency-Key", "example-post-1")
 		return req, nil
 	}
 
@@ -360,6 +366,9 @@ func main() {
 		postRequestFactory,
 	)
 	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
 		fmt.Printf("Error: %v\n", err)
 	} else {
 		defer resp.Body.Close()
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/michael-jaquier/circuitbreaker"
@@ -70,7 +70,7 @@ func (r *httpUserRepository) GetByID(ctx context.Context, id int) (*User, error)
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := r.breaker.Execute(ctx, func(ctx context.Context) error {
+	execErr := r.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = r.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -82,8 +82,8 @@ func (r *httpUserRepository) GetByID(ctx context.Context, id int) (*User, error)
 		return nil
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("user service unavailable")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return nil, fmt.Errorf("user service unavailable: %w", execErr)
 	}
 	if execErr != nil {
 		return nil, fmt.Errorf("request failed: %w", execErr)
@@ -116,7 +116,7 @@ func (r *httpUserRepository) GetAll(ctx context.Context) ([]User, error) {
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := r.breaker.Execute(ctx, func(ctx context.Context) error {
+	execErr := r.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = r.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -128,8 +128,8 @@ func (r *httpUserRepository) GetAll(ctx context.Context) ([]User, error) {
 		return nil
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("user service unavailable")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return nil, fmt.Errorf("user service unavailable: %w", execErr)
 	}
 	if execErr != nil {
 		return nil, fmt.Errorf("request failed: %w", execErr)
@@ -164,7 +164,7 @@ func (r *httpUserRepository) Create(ctx context.Context, user *User) (*User, err
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := r.breaker.Execute(ctx, func(ctx context.Context) error {
+	execErr := r.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = r.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -176,8 +176,8 @@ func (r *httpUserRepository) Create(ctx context.Context, user *User) (*User, err
 		return nil
 	})
 
-	if timer != nil {
-		return nil, fmt.Errorf("user service unavailable")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return nil, fmt.Errorf("user service unavailable: %w", execErr)
 	}
 	if execErr != nil {
 		return nil, fmt.Errorf("request failed: %w", execErr)
@@ -213,7 +213,7 @@ func (r *httpUserRepository) Update(ctx context.Context, user *User) error {
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := r.breaker.Execute(ctx, func(ctx context.Context) error {
+	execErr := r.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = r.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -225,8 +225,8 @@ func (r *httpUserRepository) Update(ctx context.Context, user *User) error {
 		return nil
 	})
 
-	if timer != nil {
-		return fmt.Errorf("user service unavailable")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return fmt.Errorf("user service unavailable: %w", execErr)
 	}
 	if execErr != nil {
 		return fmt.Errorf("request failed: %w", execErr)
@@ -251,7 +251,7 @@ func (r *httpUserRepository) Delete(ctx context.Context, id int) error {
 	var resp *http.Response
 	var httpErr error
 
-	timer, execErr := r.breaker.Execute(ctx, func(ctx context.Context) error {
+	execErr := r.breaker.Try(ctx, func(ctx context.Context) error {
 		resp, httpErr = r.client.Do(req)
 		if httpErr != nil {
 			return httpErr
@@ -263,8 +263,8 @@ func (r *httpUserRepository) Delete(ctx context.Context, id int) error {
 		return nil
 	})
 
-	if timer != nil {
-		return fmt.Errorf("user service unavailable")
+	if errors.Is(execErr, circuitbreaker.ErrCircuitOpen) {
+		return fmt.Errorf("user service unavailable: %w", execErr)
 	}
 	if execErr != nil {
 		return fmt.Errorf("request failed: %w", execErr)
@@ -293,7 +293,7 @@ func NewUserService(repo UserRepository) *UserService {
 func (s *UserService) GetUserProfile(ctx context.Context, userID int) (*User, error) {
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "circuit breaker is open") {
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			// Circuit is open, could return cached data or default profile
 			return nil, fmt.Errorf("user service temporarily unavailable, please try again later")
 		}
@@ -306,7 +306,7 @@ func (s *UserService) GetUserProfile(ctx context.Context, userID int) (*User, er
 func (s *UserService) ListUsers(ctx context.Context) ([]User, error) {
 	users, err := s.repo.GetAll(ctx)
 	if err != nil {
-		if strings.Contains(err.Error(), "circuit breaker is open") {
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			return nil, fmt.Errorf("user service temporarily unavailable, please try again later")
 		}
 		return nil, err
@@ -400,7 +400,7 @@ func main() {
 	// Circuit should now be open
 	_, err = badService.GetUserProfile(ctx, 1)
 	if err != nil {
-		if strings.Contains(err.Error(), "circuit breaker is open") {
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
 			fmt.Println("Circuit breaker successfully protecting the service!")
 		} else {
 			fmt.Printf("Second request error: %v\n", err)
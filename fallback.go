@@ -0,0 +1,65 @@
+package circuitbreaker
+
+import "context"
+
+// FallbackFunc produces a substitute outcome for a call that was rejected or failed.
+// cause is the *OpenError for a rejection, or the call's own error when it was
+// classified as a failure.
+type FallbackFunc func(ctx context.Context, cause error) error
+
+// ExecuteWithFallback runs fn like Try, but calls fallback instead of returning the
+// error when the call is rejected or fails. The fallback's error, or nil, is returned.
+// Errors that are ignored or not classified as failures are returned without a fallback.
+func (cb *circuitBreaker) ExecuteWithFallback(
+	ctx context.Context,
+	fn func(context.Context) error,
+	fallback FallbackFunc,
+) error {
+	ar := cb.allow()
+	var cause error
+	if !ar.allowed {
+		cause = cb.openError(ar)
+		cb.reject(ar)
+	} else {
+		var o outcome
+		o, cause = cb.run(ctx, ar, fn)
+		if o != outcomeFailure {
+			return cause
+		}
+	}
+
+	cb.fallbacks.Add(1)
+	err := fallback(ctx, cause)
+	if err != nil {
+		cb.fallbackFailures.Add(1)
+	}
+	return err
+}
+
+// DoWithFallback is the typed form of ExecuteWithFallback: fallback's result replaces
+// fn's when the call is rejected or fails.
+//
+//	price, err := circuitbreaker.DoWithFallback(ctx, cb, fetchPrice,
+//	    func(ctx context.Context, cause error) (Price, error) {
+//	        return cache.LastPrice(sku)
+//	    })
+func DoWithFallback[T any](
+	ctx context.Context,
+	cb CircuitBreaker,
+	fn func(context.Context) (T, error),
+	fallback func(ctx context.Context, cause error) (T, error),
+) (T, error) {
+	var result T
+	err := cb.ExecuteWithFallback(ctx,
+		func(ctx context.Context) error {
+			var err error
+			result, err = fn(ctx)
+			return err
+		},
+		func(ctx context.Context, cause error) error {
+			var err error
+			result, err = fallback(ctx, cause)
+			return err
+		})
+	return result, err
+}
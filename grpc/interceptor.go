@@ -0,0 +1,213 @@
+// Package grpc provides gRPC client interceptors that guard RPCs with circuit breakers.
+//
+// It is a separate module so the core circuitbreaker package stays free of the gRPC
+// dependency. Import it under another name to avoid clashing with google.golang.org/grpc:
+//
+//	import cbgrpc "github.com/michael-jaquier/circuitbreaker/grpc"
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(cbgrpc.UnaryClientInterceptor(cbgrpc.WithRegistry(breakers))),
+//	    grpc.WithStreamInterceptor(cbgrpc.StreamClientInterceptor(cbgrpc.WithRegistry(breakers))),
+//	)
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/michael-jaquier/circuitbreaker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// PushbackKey is the trailer key carrying how many milliseconds a caller should wait
+// before retrying, set when a call is rejected by an open circuit.
+const PushbackKey = "grpc-retry-pushback-ms"
+
+// UnaryClientInterceptor returns an interceptor that runs each unary RPC through the
+// breaker for its method. RPC errors are returned unchanged; the classifier only decides
+// whether they count against the circuit. RPCs ended by the caller's context are not
+// counted. A rejected call fails with codes.Unavailable without reaching the network.
+// It panics if the options are invalid.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	c := mustConfig(opts)
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		cb, err := c.breakerFor(method)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+
+		var rpcErr error
+		err = cb.Try(ctx, func(ctx context.Context) error {
+			rpcErr = invoker(ctx, method, req, reply, cc, callOpts...)
+			if ctx.Err() != nil {
+				// The classifier would hide codes.Canceled as a success; let the breaker
+				// see the error so a caller giving up is not counted either way.
+				return rpcErr
+			}
+			return c.failure(rpcErr)
+		})
+		return c.result(err, rpcErr, callOpts)
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that guards streaming RPCs. Each stream
+// is one call: it is admitted when opened and recorded when it ends, as a success when
+// RecvMsg reports io.EOF and through the classifier when the stream fails, whether
+// opening it or later. Streams ended by the caller's context are not counted. A slow
+// call threshold applies to the stream's whole lifetime, so long-lived streams are best
+// given their own breaker. It panics if the options are invalid.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	c := mustConfig(opts)
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cb, err := c.breakerFor(method)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
+		ticket, err := cb.Allow(ctx)
+		if err != nil {
+			return nil, c.result(err, nil, callOpts)
+		}
+		// A stream abandoned with its context must not hold a half-open probe slot.
+		stop := context.AfterFunc(ctx, ticket.Ignore)
+
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			stop()
+			c.settle(ctx, ticket, err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: stream, ctx: ctx, desc: desc, ticket: ticket, stop: stop, c: c}, nil
+	}
+}
+
+// clientStream records the outcome of a stream once RecvMsg reports how it ended.
+type clientStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	desc   *grpc.StreamDesc
+	ticket circuitbreaker.Ticket
+	stop   func() bool
+	c      config
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.stop()
+		s.ticket.Success()
+	case err != nil:
+		s.stop()
+		s.c.settle(s.ctx, s.ticket, err)
+	case !s.desc.ServerStreams:
+		// The single response of a client-streaming RPC ends the stream.
+		s.stop()
+		s.ticket.Success()
+	}
+	return err
+}
+
+// settle records a stream that ended with err: ignored if the caller's context is done,
+// a failure if the classifier says so, and a success otherwise.
+func (c config) settle(ctx context.Context, ticket circuitbreaker.Ticket, err error) {
+	switch {
+	case ctx.Err() != nil:
+		ticket.Ignore()
+	case c.failure(err) != nil:
+		ticket.Failure(err)
+	default:
+		ticket.Success()
+	}
+}
+
+func mustConfig(opts []Option) config {
+	c, err := newConfig(opts)
+	if err != nil {
+		panic("circuitbreaker/grpc: " + err.Error())
+	}
+	return c
+}
+
+// failure returns err if the classifier counts it as a failure, and nil otherwise.
+func (c config) failure(err error) error {
+	if err != nil && c.classifier(status.Code(err)) {
+		return err
+	}
+	return nil
+}
+
+// result picks the error for the caller: the RPC's own error if it ran, an Unavailable
+// status if the breaker rejected the call, or the breaker's error otherwise.
+func (c config) result(err, rpcErr error, callOpts []grpc.CallOption) error {
+	if rpcErr != nil {
+		return rpcErr
+	}
+	var openErr *circuitbreaker.OpenError
+	if errors.As(err, &openErr) {
+		setPushback(callOpts, openErr)
+		return &openStatusError{st: openStatus(openErr), err: openErr}
+	}
+	return err
+}
+
+// openStatus describes a rejection as codes.Unavailable with a RetryInfo detail.
+func openStatus(openErr *circuitbreaker.OpenError) *status.Status {
+	st := status.New(codes.Unavailable, openErr.Error())
+	withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(openErr.RetryAfter)})
+	if err != nil {
+		return st
+	}
+	return withInfo
+}
+
+// setPushback fills the trailer requested with grpc.Trailer, if any, with the pushback
+// delay, as a server would when shedding load.
+func setPushback(callOpts []grpc.CallOption, openErr *circuitbreaker.OpenError) {
+	for _, opt := range callOpts {
+		if t, ok := opt.(grpc.TrailerCallOption); ok && t.TrailerAddr != nil {
+			*t.TrailerAddr = metadata.Pairs(PushbackKey, strconv.FormatInt(openErr.RetryAfter.Milliseconds(), 10))
+		}
+	}
+}
+
+// openStatusError is a gRPC status error that still matches circuitbreaker.ErrCircuitOpen
+// and unwraps to the *circuitbreaker.OpenError.
+type openStatusError struct {
+	st  *status.Status
+	err *circuitbreaker.OpenError
+}
+
+func (e *openStatusError) Error() string {
+	return e.st.Err().Error()
+}
+
+// GRPCStatus lets status.FromError and status.Code see the Unavailable status.
+func (e *openStatusError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+func (e *openStatusError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,309 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/michael-jaquier/circuitbreaker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer answers Check and Watch with the status code configured for the test.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	code  atomic.Uint32
+	calls atomic.Int32
+}
+
+func (s *healthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.calls.Add(1)
+	if code := codes.Code(s.code.Load()); code != codes.OK {
+		return nil, status.Error(code, "configured failure")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	s.calls.Add(1)
+	if code := codes.Code(s.code.Load()); code != codes.OK {
+		return status.Error(code, "configured failure")
+	}
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+// dial starts an in-memory server and returns a health client using the interceptors.
+func dial(t *testing.T, opts ...Option) (grpc_health_v1.HealthClient, *healthServer) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	health := &healthServer{}
+	grpc_health_v1.RegisterHealthServer(server, health)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(opts...)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn), health
+}
+
+func TestUnaryInterceptorOpensOnServerErrors(t *testing.T) {
+	cb, err := circuitbreaker.NewZeroTolerance(circuitbreaker.WithCooldownTimer(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, server := dial(t, WithBreaker(cb))
+	ctx := context.Background()
+
+	server.code.Store(uint32(codes.NotFound))
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound to reach the caller, got %v", err)
+	}
+	if cb.State() != circuitbreaker.Closed {
+		t.Fatalf("Client errors should not open the circuit, got %v", cb.State())
+	}
+
+	server.code.Store(uint32(codes.Internal))
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal to reach the caller, got %v", err)
+	}
+	if cb.State() != circuitbreaker.Open {
+		t.Fatalf("Server errors should open the circuit, got %v", cb.State())
+	}
+
+	var trailer metadata.MD
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer))
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		t.Fatalf("Expected Unavailable while open, got %v", err)
+	}
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Errorf("Rejection should match ErrCircuitOpen, got %v", err)
+	}
+	if server.calls.Load() != 2 {
+		t.Errorf("Rejected call should not reach the server, got %d calls", server.calls.Load())
+	}
+
+	var retryInfo *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = info
+		}
+	}
+	if retryInfo == nil || retryInfo.RetryDelay.AsDuration() <= 0 {
+		t.Errorf("Expected a RetryInfo detail with a positive delay, got %v", st.Details())
+	}
+	if pushback := trailer.Get(PushbackKey); len(pushback) != 1 || pushback[0] == "0" {
+		t.Errorf("Expected %s trailer, got %v", PushbackKey, trailer)
+	}
+}
+
+func TestUnaryInterceptorIgnoresCallerCancellation(t *testing.T) {
+	cb, err := circuitbreaker.New()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, _ := dial(t, WithBreaker(cb))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled to reach the caller, got %v", err)
+	}
+	snap := cb.Snapshot()
+	if snap.Successes != 0 || snap.Failures != 0 {
+		t.Errorf("Cancelled RPCs should not be counted, got %d successes and %d failures",
+			snap.Successes, snap.Failures)
+	}
+	if snap.CanceledCalls != 1 {
+		t.Errorf("Expected 1 canceled call, got %d", snap.CanceledCalls)
+	}
+}
+
+func TestInterceptorPerMethodBreakers(t *testing.T) {
+	registry, err := circuitbreaker.NewRegistry(circuitbreaker.WithFailureThreshold(1))
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	client, server := dial(t, WithRegistry(registry))
+	server.code.Store(uint32(codes.Unavailable))
+
+	client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+	check, _ := registry.Get(grpc_health_v1.Health_Check_FullMethodName)
+	if check.State() != circuitbreaker.Open {
+		t.Fatalf("Expected the Check breaker to open, got %v", check.State())
+	}
+
+	server.code.Store(uint32(codes.OK))
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Other methods should be unaffected, got %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("Expected a health update, got %v", err)
+	}
+}
+
+func TestStreamInterceptorRejectsWhenOpen(t *testing.T) {
+	cb, err := circuitbreaker.NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, server := dial(t, WithBreaker(cb))
+	cb.ForceOpen()
+
+	_, err = client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) != codes.Unavailable || !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Errorf("Expected Unavailable rejection, got %v", err)
+	}
+	if server.calls.Load() != 0 {
+		t.Errorf("Rejected stream should not reach the server, got %d calls", server.calls.Load())
+	}
+}
+
+func TestStreamInterceptorOpensOnStreamErrors(t *testing.T) {
+	cb, err := circuitbreaker.NewZeroTolerance(circuitbreaker.WithCooldownTimer(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, server := dial(t, WithBreaker(cb))
+	server.code.Store(uint32(codes.Internal))
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Expected the stream to open, got %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal from the stream, got %v", err)
+	}
+	if cb.State() != circuitbreaker.Open {
+		t.Fatalf("Expected a failed stream to open the circuit, got %v", cb.State())
+	}
+
+	_, err = client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Errorf("Expected the next stream to be rejected, got %v", err)
+	}
+	if server.calls.Load() != 1 {
+		t.Errorf("Expected 1 stream to reach the server, got %d", server.calls.Load())
+	}
+}
+
+func TestStreamInterceptorRecordsSuccessAtEOF(t *testing.T) {
+	cb, err := circuitbreaker.NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, _ := dial(t, WithBreaker(cb))
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Expected the stream to open, got %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Expected a health update, got %v", err)
+	}
+	if snap := cb.Snapshot(); snap.Successes != 0 {
+		t.Errorf("Expected nothing recorded while the stream is open, got %d successes", snap.Successes)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if snap := cb.Snapshot(); snap.Successes != 1 || snap.Failures != 0 {
+		t.Errorf("Expected 1 success at EOF, got %d successes and %d failures", snap.Successes, snap.Failures)
+	}
+}
+
+func TestCustomClassifier(t *testing.T) {
+	cb, err := circuitbreaker.NewZeroTolerance()
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	defer cb.Close()
+
+	client, server := dial(t,
+		WithBreaker(cb),
+		WithClassifier(func(code codes.Code) bool { return code == codes.PermissionDenied }),
+	)
+
+	server.code.Store(uint32(codes.Internal))
+	client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if cb.State() != circuitbreaker.Closed {
+		t.Fatalf("Custom classifier should ignore Internal, got %v", cb.State())
+	}
+
+	server.code.Store(uint32(codes.PermissionDenied))
+	client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if cb.State() != circuitbreaker.Open {
+		t.Errorf("Custom classifier should count PermissionDenied, got %v", cb.State())
+	}
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	failures := []codes.Code{codes.Unavailable, codes.Internal, codes.Unknown, codes.DataLoss,
+		codes.DeadlineExceeded, codes.ResourceExhausted, codes.Unimplemented}
+	for _, code := range failures {
+		if !DefaultClassifier(code) {
+			t.Errorf("Expected %v to count as a failure", code)
+		}
+	}
+	clientErrors := []codes.Code{codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.PermissionDenied, codes.Unauthenticated,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange}
+	for _, code := range clientErrors {
+		if DefaultClassifier(code) {
+			t.Errorf("Expected %v not to count as a failure", code)
+		}
+	}
+}
+
+func TestOptionValidation(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"no breaker":     nil,
+		"nil breaker":    {WithBreaker(nil)},
+		"nil registry":   {WithRegistry(nil)},
+		"nil classifier": {WithClassifier(nil)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			UnaryClientInterceptor(opts...)
+		}()
+	}
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/michael-jaquier/circuitbreaker"
+	"google.golang.org/grpc/codes"
+)
+
+// Option configures the client interceptors.
+type Option func(*config) error
+
+type config struct {
+	breaker    circuitbreaker.CircuitBreaker
+	registry   *circuitbreaker.Registry
+	key        func(method string) string
+	classifier Classifier
+}
+
+func newConfig(opts []Option) (config, error) {
+	c := config{
+		key:        func(method string) string { return method },
+		classifier: DefaultClassifier,
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return config{}, fmt.Errorf("unable to apply configuration: %w", err)
+		}
+	}
+	if c.breaker == nil && c.registry == nil {
+		return config{}, fmt.Errorf("a breaker or a registry is required")
+	}
+	return c, nil
+}
+
+// breakerFor returns the breaker guarding method.
+func (c config) breakerFor(method string) (circuitbreaker.CircuitBreaker, error) {
+	if c.registry != nil {
+		return c.registry.Get(c.key(method))
+	}
+	return c.breaker, nil
+}
+
+// WithBreaker guards every method with the same breaker.
+func WithBreaker(cb circuitbreaker.CircuitBreaker) Option {
+	return func(c *config) error {
+		if cb == nil {
+			return fmt.Errorf("breaker must not be nil")
+		}
+		c.breaker = cb
+		return nil
+	}
+}
+
+// WithRegistry gives each method its own breaker from registry, named after the full
+// method name ("/package.Service/Method") unless WithKeyFunc is given.
+// It takes precedence over WithBreaker.
+func WithRegistry(registry *circuitbreaker.Registry) Option {
+	return func(c *config) error {
+		if registry == nil {
+			return fmt.Errorf("registry must not be nil")
+		}
+		c.registry = registry
+		return nil
+	}
+}
+
+// WithKeyFunc sets how full method names map to registry breaker names, for example
+// to share one breaker per service.
+func WithKeyFunc(key func(method string) string) Option {
+	return func(c *config) error {
+		if key == nil {
+			return fmt.Errorf("key function must not be nil")
+		}
+		c.key = key
+		return nil
+	}
+}
+
+// WithClassifier sets which status codes count as failures. The default is DefaultClassifier.
+func WithClassifier(classifier Classifier) Option {
+	return func(c *config) error {
+		if classifier == nil {
+			return fmt.Errorf("classifier must not be nil")
+		}
+		c.classifier = classifier
+		return nil
+	}
+}
+
+// Classifier reports whether an RPC that failed with code counts against the circuit.
+// Errors that are not gRPC statuses are classified as codes.Unknown.
+type Classifier func(code codes.Code) bool
+
+// DefaultClassifier counts server-side and overload codes as failures: Unavailable,
+// Internal, Unknown, DataLoss, DeadlineExceeded and ResourceExhausted, plus any code it
+// does not know. Client errors such as InvalidArgument, NotFound or PermissionDenied,
+// and Canceled, do not open the circuit.
+func DefaultClassifier(code codes.Code) bool {
+	switch code {
+	case codes.OK,
+		codes.Canceled,
+		codes.InvalidArgument,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.PermissionDenied,
+		codes.Unauthenticated,
+		codes.FailedPrecondition,
+		codes.Aborted,
+		codes.OutOfRange:
+		return false
+	default:
+		return true
+	}
+}
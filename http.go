@@ -0,0 +1,294 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPOutcome is how an HTTP exchange is counted by the breaker and whether
+// ExecuteHTTPBlocking may retry it.
+type HTTPOutcome int
+
+const (
+	// HTTPSuccess counts as a success and is not retried.
+	HTTPSuccess HTTPOutcome = iota
+	// HTTPRetryable counts as a failure and is retried.
+	HTTPRetryable
+	// HTTPNonRetryable counts as a failure and is returned to the caller without retrying.
+	HTTPNonRetryable
+	// HTTPIgnore is neither counted nor retried.
+	HTTPIgnore
+)
+
+// HTTPClassifier decides the outcome of an HTTP exchange. Exactly one of resp and err
+// is non-nil. A classifier that inspects the body must leave it readable for the caller.
+type HTTPClassifier func(resp *http.Response, err error) HTTPOutcome
+
+// DefaultHTTPClassifier is used by ExecuteHTTPBlocking and Transport unless
+// WithHTTPClassifier is set. Transport errors and 408, 429 and 5xx responses are
+// retryable failures; every other response, including client errors that say nothing
+// about the upstream's health, counts as a success.
+func DefaultHTTPClassifier(resp *http.Response, err error) HTTPOutcome {
+	if err != nil {
+		return HTTPRetryable
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests,
+		code >= 500 && code <= 599:
+		return HTTPRetryable
+	default:
+		return HTTPSuccess
+	}
+}
+
+// StatusRule maps the status codes from From to To, inclusive, to an outcome.
+type StatusRule struct {
+	From, To int
+	Outcome  HTTPOutcome
+}
+
+// Status returns a rule for a single status code.
+func Status(code int, outcome HTTPOutcome) StatusRule {
+	return StatusRule{From: code, To: code, Outcome: outcome}
+}
+
+// StatusRange returns a rule for the status codes from from to to, inclusive.
+func StatusRange(from, to int, outcome HTTPOutcome) StatusRule {
+	return StatusRule{From: from, To: to, Outcome: outcome}
+}
+
+// StatusClassifier returns a classifier applying the first rule that matches the
+// response status. Transport errors and unmatched statuses are classified by
+// DefaultHTTPClassifier.
+//
+//	circuitbreaker.StatusClassifier(
+//	    circuitbreaker.Status(http.StatusConflict, circuitbreaker.HTTPRetryable),
+//	    circuitbreaker.Status(http.StatusNotImplemented, circuitbreaker.HTTPNonRetryable),
+//	)
+func StatusClassifier(rules ...StatusRule) HTTPClassifier {
+	rules = slices.Clone(rules)
+	return func(resp *http.Response, err error) HTTPOutcome {
+		if err == nil {
+			for _, rule := range rules {
+				if resp.StatusCode >= rule.From && resp.StatusCode <= rule.To {
+					return rule.Outcome
+				}
+			}
+		}
+		return DefaultHTTPClassifier(resp, err)
+	}
+}
+
+// classifyHTTP applies the breaker's HTTP classifier.
+func (c config) classifyHTTP(resp *http.Response, err error) HTTPOutcome {
+	if c.httpClassifier != nil {
+		return c.httpClassifier(resp, err)
+	}
+	return DefaultHTTPClassifier(resp, err)
+}
+
+// sendWithin sends req with send under the call timeout, if any. callCtx is the call's
+// context: ending before the response headers arrive cancels the request, but once they
+// have, the body stays readable after the call returns, until the caller closes it.
+func (c config) sendWithin(callCtx context.Context, req *http.Request,
+	send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if c.callTimeout == 0 {
+		return send(req)
+	}
+	ctx, cancel := context.WithCancelCause(req.Context())
+	stop := context.AfterFunc(callCtx, func() { cancel(context.Cause(callCtx)) })
+	resp, err := send(req.WithContext(ctx))
+	stop()
+	if err != nil || resp == nil {
+		cancel(nil)
+		return resp, err
+	}
+	// An upgraded connection's body must stay an io.ReadWriteCloser.
+	if _, upgraded := resp.Body.(io.Writer); !upgraded {
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	}
+	return resp, nil
+}
+
+// cancelOnClose releases the request's context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelCauseFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
+
+// httpError returns the error the caller sees for an exchange: the transport error,
+// or a status error for responses of 400 and above and for those counted as failures.
+// It returns nil for any other response.
+func httpError(resp *http.Response, err error, o HTTPOutcome) error {
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 400 && o != HTTPRetryable && o != HTTPNonRetryable {
+		return nil
+	}
+	return newHTTPStatusError(resp, o == HTTPRetryable)
+}
+
+// mayResend reports whether req can be sent again after failing with err without
+// risking a duplicate: its method is idempotent, it carries an Idempotency-Key header,
+// it failed to dial and so never left, or non-idempotent retries are allowed. An empty
+// method means GET, as it does for http.Client.
+func (c config) mayResend(req *http.Request, err error) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return c.nonIdempotent || req.Header.Get("Idempotency-Key") != "" || notSent(err)
+}
+
+// notSent reports whether err proves the request never reached the server.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// maxBodyExcerpt bounds how much of a response body an HTTPStatusError keeps.
+const maxBodyExcerpt = 1 << 10
+
+// HTTPStatusError describes a response that was not successful. ExecuteHTTPBlocking
+// returns it, possibly wrapped, so callers can use errors.As instead of parsing messages.
+type HTTPStatusError struct {
+	StatusCode int
+	Header     http.Header
+	// Method and URL identify the request. The URL has any password redacted.
+	Method string
+	URL    string
+	// Body holds up to the first 1 KiB of the response body, read before a retryable
+	// response is drained. A response returned to the caller keeps its full body.
+	Body []byte
+	// Retryable reports whether the response was classified as HTTPRetryable.
+	Retryable bool
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Retryable {
+		return fmt.Sprintf("retryable HTTP error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("non-retryable HTTP error: status %d", e.StatusCode)
+}
+
+// newHTTPStatusError describes resp without reading its body.
+func newHTTPStatusError(resp *http.Response, retryable bool) *HTTPStatusError {
+	e := &HTTPStatusError{StatusCode: resp.StatusCode, Header: resp.Header, Retryable: retryable}
+	if req := resp.Request; req != nil {
+		e.Method = req.Method
+		if req.URL != nil {
+			e.URL = req.URL.Redacted()
+		}
+	}
+	return e
+}
+
+// bodyExcerpt reads the start of resp's body and puts it back in front of the rest,
+// so the caller can still read the whole body.
+func bodyExcerpt(resp *http.Response) []byte {
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyExcerpt))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(excerpt), resp.Body), resp.Body}
+	return excerpt
+}
+
+// retryAfter returns the delay a 429 or 503 response asks for in its Retry-After
+// header, given either as delta-seconds or as an HTTP-date. It returns zero when the
+// header is absent, malformed or already in the past.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(min(seconds, int64(maxRetryAfter/time.Second))) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return min(date.Sub(now), maxRetryAfter)
+	}
+	return 0
+}
+
+// maxRetryAfter bounds the delay taken from a Retry-After header, so a misbehaving
+// server cannot stall callers or hold the circuit open indefinitely.
+const maxRetryAfter = time.Hour
+
+// HTTPOption configures the HTTP integrations built on circuit breakers.
+type HTTPOption func(*httpConfig) error
+
+type httpConfig struct {
+	key         func(*http.Request) string
+	breakerOpts []Option
+	registry    *Registry
+}
+
+func newHTTPConfig(key func(*http.Request) string, opts []HTTPOption) (httpConfig, error) {
+	c := httpConfig{key: key}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return httpConfig{}, fmt.Errorf("unable to apply configuration: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// hostKey keys breakers by the request's host and port.
+func hostKey(req *http.Request) string {
+	return req.URL.Host
+}
+
+// WithKeyFunc sets how requests are mapped to breaker names, for example to give
+// each API route its own breaker instead of one per host.
+func WithKeyFunc(key func(*http.Request) string) HTTPOption {
+	return func(c *httpConfig) error {
+		if key == nil {
+			return fmt.Errorf("key function must not be nil")
+		}
+		c.key = key
+		return nil
+	}
+}
+
+// WithBreakerOptions sets the options every breaker created by a Transport uses.
+func WithBreakerOptions(opts ...Option) HTTPOption {
+	return func(c *httpConfig) error {
+		c.breakerOpts = append(c.breakerOpts, opts...)
+		return nil
+	}
+}
+
+// WithRegistry makes Middleware take a breaker per request from registry, keyed by
+// the key function, instead of using a single breaker.
+func WithRegistry(registry *Registry) HTTPOption {
+	return func(c *httpConfig) error {
+		if registry == nil {
+			return fmt.Errorf("registry must not be nil")
+		}
+		c.registry = registry
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package circuitbreaker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware sheds load on an HTTP server: requests run through cb, handlers that
+// respond 5xx, panic or exceed the slow call threshold count as failures, and while the
+// circuit is open requests are answered with 503 Service Unavailable and a Retry-After
+// header instead of reaching the handler.
+//
+// With WithRegistry, each request uses the registry's breaker for its key instead of cb,
+// giving per-route breakers; the key defaults to the request path, so pair it with
+// WithKeyFunc when paths are unbounded. The handler runs with the breaker's call context,
+// so WithCallTimeout bounds it. Middleware panics if an option is invalid, or if
+// WithBreakerOptions is given, since Middleware never creates breakers itself.
+func Middleware(cb CircuitBreaker, opts ...HTTPOption) func(http.Handler) http.Handler {
+	c, err := newHTTPConfig(pathKey, opts)
+	if err != nil {
+		panic(err)
+	}
+	if cb == nil && c.registry == nil {
+		panic("circuitbreaker: Middleware needs a breaker or a registry")
+	}
+	if c.breakerOpts != nil {
+		panic("circuitbreaker: Middleware uses existing breakers, use WithRegistry instead of WithBreakerOptions")
+	}
+
+	breaker := func(r *http.Request) (CircuitBreaker, error) {
+		if c.registry != nil {
+			return c.registry.Get(c.key(r))
+		}
+		return cb, nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cb, err := breaker(r)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			err = cb.Try(r.Context(), func(ctx context.Context) error {
+				next.ServeHTTP(sw, r.WithContext(ctx))
+				if sw.status >= 500 {
+					return &HTTPStatusError{StatusCode: sw.status, Header: sw.Header(),
+						Method: r.Method, URL: r.URL.Redacted(), Retryable: true}
+				}
+				return nil
+			})
+
+			var openErr *OpenError
+			var panicErr *PanicError
+			switch {
+			case errors.As(err, &openErr) && !sw.wroteHeader:
+				w.Header().Set("Retry-After", retryAfterSeconds(openErr.RetryAfter))
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			case errors.As(err, &panicErr) && !sw.wroteHeader:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+// pathKey keys breakers by request path.
+func pathKey(r *http.Request) string {
+	return r.URL.Path
+}
+
+// retryAfterSeconds formats d as a Retry-After delay, rounded up to whole seconds.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
+
+// statusWriter records the status code written by a handler. It forwards Flush and
+// Hijack, and Unwrap lets http.ResponseController reach the underlying writer.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first final status. Informational responses such as
+// 103 Early Hints may precede it and are not recorded; 101 Switching Protocols is final.
+func (w *statusWriter) WriteHeader(code int) {
+	informational := code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols
+	if !w.wroteHeader && !informational {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = http.StatusOK, true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.status, w.wroteHeader = http.StatusOK, true
+		}
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection to the handler. The response status is then unknown,
+// and the call is counted as a success.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
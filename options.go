@@ -6,13 +6,32 @@ import (
 )
 
 type config struct {
-	resetTimer       int64
-	cooldownTimer    int64
-	successToClose   int64
-	windowSize       int64
-	maximumProbes    int64
-	failureThreshold int64
-	clock            Clock
+	resetTimer         int64
+	cooldownTimer      int64
+	successToClose     int64
+	windowSize         int64
+	maximumProbes      int64
+	failureThreshold   int64
+	countWindow        int64
+	failureRate        float64
+	minimumCalls       int64
+	slowCallThreshold  int64
+	slowCallRate       float64
+	windowBuckets      int64
+	clock              Clock
+	cooldownStrategy   CooldownStrategy
+	name               string
+	onStateChange      []StateChangeFunc
+	eventBuffer        int
+	isFailure          func(error) bool
+	ignoredErrors      []error
+	panicPolicy        PanicPolicy
+	callTimeout        int64
+	countCancellations bool
+	retryPolicy        *RetryPolicy
+	retryAfterCooldown bool
+	httpClassifier     HTTPClassifier
+	nonIdempotent      bool
 }
 
 func defaultConfig() config {
@@ -23,10 +42,66 @@ func defaultConfig() config {
 		windowSize:       int64(240 * time.Second),
 		maximumProbes:    1,
 		failureThreshold: 3,
+		windowBuckets:    10,
+		minimumCalls:     10,
 		clock:            realClock{},
+		eventBuffer:      64,
 	}
 }
 
+// newConfig applies opts to the defaults and validates the result.
+func newConfig(opts []Option) (config, error) {
+	c := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return config{}, fmt.Errorf("unable to apply configuration: %w", err)
+		}
+	}
+	if err := c.validate(); err != nil {
+		return config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return c, nil
+}
+
+// validate checks constraints that involve more than one option.
+func (c config) validate() error {
+	if c.countWindow > 0 && c.failureRate > 0 && c.minimumCalls > c.countWindow {
+		return fmt.Errorf("minimum calls %d can never be reached in a sliding window of %d calls",
+			c.minimumCalls, c.countWindow)
+	}
+	if c.countWindow > 0 && c.failureRate == 0 && c.failureThreshold > c.countWindow {
+		return fmt.Errorf("failure threshold %d can never be reached in a sliding window of %d calls",
+			c.failureThreshold, c.countWindow)
+	}
+	if c.slowCallRate > 0 && c.slowCallThreshold == 0 {
+		return fmt.Errorf("slow call rate threshold requires a slow call threshold")
+	}
+	if c.countWindow > 0 && c.slowCallRate > 0 && c.minimumCalls > c.countWindow {
+		return fmt.Errorf("minimum calls %d can never be reached in a sliding window of %d calls",
+			c.minimumCalls, c.countWindow)
+	}
+	if c.windowSize/c.windowBuckets <= 0 {
+		return fmt.Errorf("window size %v is too small for %d buckets",
+			time.Duration(c.windowSize), c.windowBuckets)
+	}
+	return nil
+}
+
+func (c config) newWindow() window {
+	if c.countWindow > 0 {
+		return newCountWindow(c.countWindow)
+	}
+	return newTimeWindow(c.clock, c.windowSize, c.windowBuckets)
+}
+
+// cooldown returns the configured cooldown strategy, defaulting to the constant cooldown timer.
+func (c config) cooldown() CooldownStrategy {
+	if c.cooldownStrategy != nil {
+		return c.cooldownStrategy
+	}
+	return ConstantCooldown(time.Duration(c.cooldownTimer))
+}
+
 // Option configures a circuit breaker.
 type Option func(*config) error
 
@@ -39,6 +114,7 @@ func WithClock(clock Clock) Option {
 }
 
 // WithWindowSize sets the time window for tracking failures.
+// The window rolls forward one bucket at a time; see WithWindowBuckets.
 func WithWindowSize(windowSize time.Duration) Option {
 	return func(c *config) error {
 		if windowSize <= 0 {
@@ -49,6 +125,18 @@ func WithWindowSize(windowSize time.Duration) Option {
 	}
 }
 
+// WithWindowBuckets sets how many buckets the time window is divided into.
+// More buckets make failures age out more smoothly at a small memory cost.
+func WithWindowBuckets(buckets int64) Option {
+	return func(c *config) error {
+		if buckets <= 0 {
+			return fmt.Errorf("window buckets must be >0")
+		}
+		c.windowBuckets = buckets
+		return nil
+	}
+}
+
 // WithMaximumProbes sets the maximum number of concurrent probes in half-open state.
 func WithMaximumProbes(probes int64) Option {
 	return func(c *config) error {
@@ -82,6 +170,18 @@ func WithCooldownTimer(timer time.Duration) Option {
 	}
 }
 
+// WithCooldownStrategy sets how the open duration evolves when half-open probes keep
+// failing. It replaces the constant duration set by WithCooldownTimer.
+func WithCooldownStrategy(strategy CooldownStrategy) Option {
+	return func(c *config) error {
+		if strategy == nil {
+			return fmt.Errorf("cooldown strategy must not be nil")
+		}
+		c.cooldownStrategy = strategy
+		return nil
+	}
+}
+
 // WithResetTimer sets the duration before resetting the failure window.
 func WithResetTimer(timer time.Duration) Option {
 	return func(c *config) error {
@@ -94,6 +194,7 @@ func WithResetTimer(timer time.Duration) Option {
 }
 
 // WithFailureThreshold sets the number of failures required to open the circuit.
+// It is ignored when WithFailureRateThreshold is set.
 func WithFailureThreshold(threshold int64) Option {
 	return func(c *config) error {
 		if threshold <= 0 {
@@ -103,3 +204,227 @@ func WithFailureThreshold(threshold int64) Option {
 		return nil
 	}
 }
+
+// WithSlidingCountWindow makes the breaker evaluate only the outcomes of the last n calls
+// instead of counting failures over WithWindowSize. The circuit opens once the failures
+// among those calls reach the failure threshold.
+func WithSlidingCountWindow(n int64) Option {
+	return func(c *config) error {
+		if n <= 0 {
+			return fmt.Errorf("sliding window size must be >0")
+		}
+		c.countWindow = n
+		return nil
+	}
+}
+
+// WithName sets the name reported in events, errors and snapshots.
+func WithName(name string) Option {
+	return func(c *config) error {
+		c.name = name
+		return nil
+	}
+}
+
+// WithOnStateChange registers a function called after every state change.
+// Listeners run on the breaker's background goroutine, never on the caller's;
+// the option may be given several times.
+func WithOnStateChange(fn StateChangeFunc) Option {
+	return func(c *config) error {
+		if fn == nil {
+			return fmt.Errorf("state change listener must not be nil")
+		}
+		c.onStateChange = append(c.onStateChange, fn)
+		return nil
+	}
+}
+
+// WithEventBufferSize sets how many undelivered events each subscriber and the
+// state-change listeners may queue before further events are dropped.
+func WithEventBufferSize(size int) Option {
+	return func(c *config) error {
+		if size <= 0 {
+			return fmt.Errorf("event buffer size must be >0")
+		}
+		c.eventBuffer = size
+		return nil
+	}
+}
+
+// WithFailureRateThreshold makes the breaker open when the percentage of failed calls in
+// the window reaches percent, instead of using an absolute failure count.
+// The rate is only evaluated once the window holds WithMinimumCalls calls.
+func WithFailureRateThreshold(percent float64) Option {
+	return func(c *config) error {
+		if percent <= 0 || percent > 100 {
+			return fmt.Errorf("failure rate threshold must be in (0, 100]")
+		}
+		c.failureRate = percent
+		return nil
+	}
+}
+
+// WithSlowCallThreshold sets how long a call may take before it is counted as slow,
+// measured with the breaker's Clock. Slow calls are counted even when they succeed.
+func WithSlowCallThreshold(threshold time.Duration) Option {
+	return func(c *config) error {
+		if threshold <= 0 {
+			return fmt.Errorf("slow call threshold must be >0")
+		}
+		c.slowCallThreshold = threshold.Nanoseconds()
+		return nil
+	}
+}
+
+// WithSlowCallRateThreshold makes the breaker open when the percentage of slow calls in
+// the window reaches percent, once the window holds WithMinimumCalls calls.
+// While half-open, a slow probe counts as a failed one.
+func WithSlowCallRateThreshold(percent float64) Option {
+	return func(c *config) error {
+		if percent <= 0 || percent > 100 {
+			return fmt.Errorf("slow call rate threshold must be in (0, 100]")
+		}
+		c.slowCallRate = percent
+		return nil
+	}
+}
+
+// WithMinimumCalls sets how many calls the window must hold before rate thresholds
+// are evaluated, so a handful of early failures cannot open the circuit.
+func WithMinimumCalls(n int64) Option {
+	return func(c *config) error {
+		if n <= 0 {
+			return fmt.Errorf("minimum calls must be >0")
+		}
+		c.minimumCalls = n
+		return nil
+	}
+}
+
+// WithIsFailure sets the predicate deciding whether a non-nil error returned by a call
+// counts as a failure. Errors for which it returns false are counted as successes; the
+// caller still receives the original error. By default every non-nil error is a failure.
+func WithIsFailure(isFailure func(error) bool) Option {
+	return func(c *config) error {
+		if isFailure == nil {
+			return fmt.Errorf("failure predicate must not be nil")
+		}
+		c.isFailure = isFailure
+		return nil
+	}
+}
+
+// WithIgnoredErrors makes calls failing with an error matching any of targets, as
+// reported by errors.Is, count as neither success nor failure. The caller still
+// receives the original error. Ignored errors take precedence over WithIsFailure;
+// the option may be given several times.
+func WithIgnoredErrors(targets ...error) Option {
+	return func(c *config) error {
+		for _, target := range targets {
+			if target == nil {
+				return fmt.Errorf("ignored errors must not be nil")
+			}
+		}
+		c.ignoredErrors = append(c.ignoredErrors, targets...)
+		return nil
+	}
+}
+
+// PanicPolicy decides what happens after a call panics. Either way the panic is
+// recorded as a failure and any half-open probe slot is released first.
+type PanicPolicy int
+
+// Panic policies.
+const (
+	// PanicRepanic re-raises the original panic value in the caller's goroutine.
+	PanicRepanic PanicPolicy = iota
+	// PanicReturnError returns a *PanicError carrying the value and stack instead.
+	PanicReturnError
+)
+
+// WithPanicPolicy sets how panics raised by calls are surfaced. The default is PanicRepanic.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(c *config) error {
+		if policy != PanicRepanic && policy != PanicReturnError {
+			return fmt.Errorf("unknown panic policy %d", policy)
+		}
+		c.panicPolicy = policy
+		return nil
+	}
+}
+
+// WithCallTimeout bounds each call: fn receives a context that expires after timeout.
+// It applies to Execute, ExecuteBlocking, ExecuteGRPCBlocking, Try, the Do functions,
+// Middleware and the gRPC unary interceptor. ExecuteHTTPBlocking and Transport bound
+// only the wait for the response headers, leaving the body readable; Allow and the
+// gRPC stream interceptor apply no timeout. A call failing because of that deadline
+// returns an error wrapping ErrCallTimeout and is always counted as a failure. A deadline or cancellation from the caller's context is
+// not a timeout: such calls are ignored and counted in Snapshot.CanceledCalls, unless
+// WithCountCancellations makes them classified like any other error.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout <= 0 {
+			return fmt.Errorf("call timeout must be >0")
+		}
+		c.callTimeout = timeout.Nanoseconds()
+		return nil
+	}
+}
+
+// WithRetryPolicy bounds the retries of ExecuteBlocking, ExecuteHTTPBlocking and
+// ExecuteGRPCBlocking. Without it ExecuteBlocking returns the first error it gets,
+// while the HTTP and gRPC methods retry with backoff until their context is done.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		c.retryPolicy = &policy
+		return nil
+	}
+}
+
+// WithRetryAfterCooldown opens the circuit for as long as the Retry-After header of a
+// 429 or 503 response asks, when ExecuteHTTPBlocking or a Transport receives one that
+// is classified as HTTPRetryable, so every caller sharing the breaker backs off and not
+// only the one that saw it.
+// An already open circuit is kept open until the later of its cooldown and the header.
+func WithRetryAfterCooldown() Option {
+	return func(c *config) error {
+		c.retryAfterCooldown = true
+		return nil
+	}
+}
+
+// WithHTTPClassifier replaces DefaultHTTPClassifier for ExecuteHTTPBlocking and
+// Transport, for upstreams that report errors in 200 responses, use other statuses for
+// transient conditions, or return errors that retrying cannot fix.
+func WithHTTPClassifier(classifier HTTPClassifier) Option {
+	return func(c *config) error {
+		if classifier == nil {
+			return fmt.Errorf("HTTP classifier must not be nil")
+		}
+		c.httpClassifier = classifier
+		return nil
+	}
+}
+
+// WithNonIdempotentRetries lets ExecuteHTTPBlocking resend requests whose method is not
+// idempotent, such as POST, after a failure the server may have processed. Only use it
+// when the upstream deduplicates requests some other way.
+func WithNonIdempotentRetries() Option {
+	return func(c *config) error {
+		c.nonIdempotent = true
+		return nil
+	}
+}
+
+// WithCountCancellations classifies errors from calls whose caller's context was
+// already done like any other error. By default such calls count as neither success
+// nor failure, so clients giving up cannot open the circuit against a healthy dependency.
+func WithCountCancellations() Option {
+	return func(c *config) error {
+		c.countCancellations = true
+		return nil
+	}
+}
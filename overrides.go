@@ -0,0 +1,52 @@
+package circuitbreaker
+
+import "time"
+
+// forcedOpenPollInterval is how long callers rejected by a forced-open breaker are
+// told to wait. A forced state has no scheduled end, so this only paces retries.
+const forcedOpenPollInterval = time.Second
+
+// ForceOpen rejects every call until Reset or TransitionToHalfOpen is called.
+// Outcomes of calls already in flight are discarded.
+func (cb *circuitBreaker) ForceOpen() {
+	cb.forceState(ForcedOpen)
+}
+
+// ForceClosed admits every call and stops recording outcomes until Reset or
+// TransitionToHalfOpen is called. Use it when the breaker is misclassifying failures.
+func (cb *circuitBreaker) ForceClosed() {
+	cb.forceState(ForcedClosed)
+}
+
+// Disable admits every call while still recording outcomes, so Snapshot keeps
+// reporting what the breaker would have seen, but never opens the circuit.
+// Call Reset or TransitionToHalfOpen to resume automatic behaviour.
+func (cb *circuitBreaker) Disable() {
+	cb.forceState(Disabled)
+}
+
+// Reset closes the breaker, clears its counters and cooldown backoff, and resumes
+// automatic behaviour from any state.
+func (cb *circuitBreaker) Reset() {
+	cb.forceState(Closed)
+}
+
+// TransitionToHalfOpen moves the breaker to half-open from any state, so the next
+// calls are admitted as probes. Probes already in flight keep their slots.
+func (cb *circuitBreaker) TransitionToHalfOpen() {
+	cb.forceState(HalfOpen)
+}
+
+// forceState moves the breaker to state regardless of its current state.
+// The state-change event is only published if the state actually changed.
+func (cb *circuitBreaker) forceState(to State) {
+	cb.transitionMu.Lock()
+	from := State(cb.state.Swap(int64(to)))
+	cb.enterStateLocked(to)
+	cb.halfOpenWhen.Store(0)
+	cb.transitionMu.Unlock()
+
+	if from != to {
+		cb.publish(EventStateChange, from, to, nil)
+	}
+}
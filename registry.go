@@ -0,0 +1,146 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrRegistryClosed is returned by Registry.Get after the registry has been closed.
+var ErrRegistryClosed = errors.New("circuit breaker registry is closed")
+
+// Registry lazily creates and holds one named circuit breaker per dependency.
+// Every breaker is built from the registry's default options, then any options
+// registered for its name with Override, and is named after its key.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	defaults  []Option
+	overrides map[string][]Option
+	breakers  map[string]CircuitBreaker
+	closed    bool
+
+	// named, if set, returns options for the breaker called name that depend on it,
+	// applied after the defaults and before any override.
+	named func(name string) []Option
+}
+
+// NewRegistry creates a registry whose breakers share the given default options.
+// The defaults are validated once here, so Get only fails on bad overrides.
+func NewRegistry(defaults ...Option) (*Registry, error) {
+	if _, err := newConfig(defaults); err != nil {
+		return nil, err
+	}
+	return &Registry{
+		defaults:  defaults,
+		overrides: make(map[string][]Option),
+		breakers:  make(map[string]CircuitBreaker),
+	}, nil
+}
+
+// Override sets options applied on top of the defaults when the breaker for name is
+// created, replacing any earlier override for that name. A breaker that already
+// exists keeps its configuration until it is removed.
+func (r *Registry) Override(name string, opts ...Option) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[name] = opts
+}
+
+// Get returns the breaker for name, creating it on first use.
+// Every call with the same name returns the same instance until it is removed.
+func (r *Registry) Get(name string) (CircuitBreaker, error) {
+	r.mu.RLock()
+	cb, ok := r.breakers[name]
+	closed := r.closed
+	r.mu.RUnlock()
+	if ok {
+		return cb, nil
+	}
+	if closed {
+		return nil, ErrRegistryClosed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok := r.breakers[name]; ok {
+		return cb, nil
+	}
+	if r.closed {
+		return nil, ErrRegistryClosed
+	}
+
+	var named []Option
+	if r.named != nil {
+		named = r.named(name)
+	}
+	opts := slices.Concat(r.defaults, named, r.overrides[name], []Option{WithName(name)})
+	cb, err := New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker %q: %w", name, err)
+	}
+	r.breakers[name] = cb
+	return cb, nil
+}
+
+// Range calls fn for each breaker created so far, in name order, until fn returns false.
+// fn may call other Registry methods.
+func (r *Registry) Range(fn func(name string, cb CircuitBreaker) bool) {
+	r.mu.RLock()
+	names := r.namesLocked()
+	breakers := make([]CircuitBreaker, len(names))
+	for i, name := range names {
+		breakers[i] = r.breakers[name]
+	}
+	r.mu.RUnlock()
+
+	for i, name := range names {
+		if !fn(name, breakers[i]) {
+			return
+		}
+	}
+}
+
+// Names returns the sorted names of the breakers created so far.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namesLocked()
+}
+
+func (r *Registry) namesLocked() []string {
+	names := make([]string, 0, len(r.breakers))
+	for name := range r.breakers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Remove closes and forgets the breaker for name, reporting whether it existed.
+// The next Get creates a fresh breaker with the current options.
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	cb, ok := r.breakers[name]
+	delete(r.breakers, name)
+	r.mu.Unlock()
+
+	if ok {
+		cb.Close()
+	}
+	return ok
+}
+
+// Close closes every breaker in the registry. Get fails with ErrRegistryClosed afterwards.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	breakers := r.breakers
+	r.breakers = make(map[string]CircuitBreaker)
+	r.closed = true
+	r.mu.Unlock()
+
+	for _, cb := range breakers {
+		cb.Close()
+	}
+}
@@ -0,0 +1,142 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy bounds how ExecuteBlocking, ExecuteHTTPBlocking and ExecuteGRPCBlocking
+// retry calls that the breaker admitted but that failed. Rejected calls are not
+// attempts: the blocking methods always wait for the breaker to admit the next call,
+// on top of any backoff, and never beyond the caller's context.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of admitted calls, including the first.
+	// Zero means no limit.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt. Zero means 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Zero means 10s.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each failed attempt. Zero means 2.
+	Multiplier float64
+	// Jitter is the fraction of each backoff picked at random: 0.2 waits between
+	// 80% and 120% of it. Zero means no jitter.
+	Jitter float64
+	// MaxElapsedTime stops retrying once the next attempt would start later than
+	// this long after the first one. Zero means no limit.
+	MaxElapsedTime time.Duration
+	// Retryable reports whether a failed attempt should be retried. Nil retries
+	// every error the method itself considers retryable. Errors the breaker does not
+	// count as failures are never retried.
+	Retryable func(error) bool
+}
+
+// defaultRetryPolicy is used by ExecuteHTTPBlocking and ExecuteGRPCBlocking when no
+// policy is set. It never gives up but keeps a failing upstream from being hit in a
+// tight loop while the breaker is still closed.
+var defaultRetryPolicy = RetryPolicy{Jitter: 0.2}
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultMultiplier     = 2
+)
+
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.MaxAttempts < 0:
+		return fmt.Errorf("max attempts must be >=0")
+	case p.InitialBackoff < 0:
+		return fmt.Errorf("initial backoff must be >=0")
+	case p.MaxBackoff < 0:
+		return fmt.Errorf("max backoff must be >=0")
+	case p.Multiplier != 0 && p.Multiplier < 1:
+		return fmt.Errorf("multiplier must be >=1")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("jitter must be between 0 and 1")
+	case p.MaxElapsedTime < 0:
+		return fmt.Errorf("max elapsed time must be >=0")
+	}
+	return nil
+}
+
+// retrier tracks the attempts of one blocking call against its policy.
+type retrier struct {
+	policy   *RetryPolicy
+	clock    Clock
+	start    time.Time
+	attempts int
+	backoff  time.Duration
+}
+
+// newRetrier starts tracking a blocking call. A nil policy never retries.
+func newRetrier(policy *RetryPolicy, clock Clock) *retrier {
+	r := &retrier{policy: policy, clock: clock, start: clock.Now()}
+	if policy != nil {
+		r.backoff = policy.InitialBackoff
+		if r.backoff == 0 {
+			r.backoff = defaultInitialBackoff
+		}
+	}
+	return r
+}
+
+// next is called after a failed attempt. It returns how long to wait before the
+// next one, never less than floor, or the error to give the caller: err itself when
+// it is not retryable, or an error wrapping both ErrRetriesExhausted and err when the
+// policy is used up.
+func (r *retrier) next(err error, floor time.Duration) (time.Duration, error) {
+	p := r.policy
+	if p == nil || (p.Retryable != nil && !p.Retryable(err)) {
+		return 0, err
+	}
+	r.attempts++
+	if p.MaxAttempts > 0 && r.attempts >= p.MaxAttempts {
+		return 0, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, r.attempts, err)
+	}
+
+	multiplier, maximum := p.Multiplier, p.MaxBackoff
+	if multiplier == 0 {
+		multiplier = defaultMultiplier
+	}
+	if maximum == 0 {
+		maximum = defaultMaxBackoff
+	}
+
+	wait := r.backoff
+	if p.Jitter > 0 {
+		spread := time.Duration(float64(wait) * p.Jitter)
+		wait = randomBetween(wait-spread, wait+spread)
+	}
+	wait = max(min(wait, maximum), floor)
+	if p.MaxElapsedTime > 0 && r.clock.Now().Add(wait).Sub(r.start) > p.MaxElapsedTime {
+		return 0, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, r.attempts, err)
+	}
+
+	r.backoff = min(time.Duration(float64(r.backoff)*multiplier), maximum)
+	return wait, nil
+}
+
+// sleep waits for d or until ctx is done, returning ctx.Err() in the latter case.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryPolicy returns the policy set with WithRetryPolicy, or def when there is none.
+func (cb *circuitBreaker) retryPolicy(def *RetryPolicy) *RetryPolicy {
+	if cb.config.retryPolicy != nil {
+		return cb.config.retryPolicy
+	}
+	return def
+}
@@ -0,0 +1,145 @@
+package circuitbreaker
+
+import "time"
+
+// Settings is a read-only view of the configuration a circuit breaker was built with.
+type Settings struct {
+	Name             string
+	FailureThreshold int64
+	SuccessToClose   int64
+	MaximumProbes    int64
+	CooldownTimer    time.Duration
+	WindowSize       time.Duration
+	WindowBuckets    int64
+	ResetTimer       time.Duration
+	// SlidingCountWindow is the number of calls evaluated, or 0 for a time window.
+	SlidingCountWindow int64
+	// FailureRateThreshold is the failure percentage that opens the circuit,
+	// or 0 when FailureThreshold is used instead.
+	FailureRateThreshold float64
+	MinimumCalls         int64
+	// SlowCallThreshold is the duration above which a call counts as slow, or 0.
+	SlowCallThreshold time.Duration
+	// SlowCallRateThreshold is the slow call percentage that opens the circuit, or 0.
+	SlowCallRateThreshold float64
+	// CallTimeout is the deadline given to each call, or 0 for none.
+	CallTimeout time.Duration
+}
+
+// Snapshot is an immutable, point-in-time view of a circuit breaker.
+type Snapshot struct {
+	State State
+	// Failures and Successes are the outcomes currently counted towards a decision:
+	// the failure window while closed or disabled, the probe results while half-open.
+	Failures  int64
+	Successes int64
+	// SlowCalls counts calls, failed or not, that exceeded the slow call threshold.
+	SlowCalls int64
+	// HalfOpenAt is when an open breaker will admit its first probe.
+	// It is the zero time unless State is Open.
+	HalfOpenAt time.Time
+	// ConsecutiveOpens counts how many times the breaker has opened since it was last
+	// closed, including reopens after failed half-open probes.
+	ConsecutiveOpens int64
+	// Cooldown is the open duration chosen for the current or most recent opening,
+	// or zero while the breaker has not opened since it was last closed.
+	Cooldown time.Duration
+	// ProbesInUse is the number of half-open probe slots currently held.
+	ProbesInUse int
+	// DroppedEvents counts events discarded because a subscriber or the
+	// state-change listeners fell behind.
+	DroppedEvents int64
+	// Fallbacks counts fallbacks run by ExecuteWithFallback since the breaker was
+	// created, and FallbackFailures those that returned an error. Neither is reset
+	// by state changes.
+	Fallbacks        int64
+	FallbackFailures int64
+	// TimedOutCalls counts calls that exceeded the call timeout since the breaker
+	// was created. Each is also recorded as a failure.
+	TimedOutCalls int64
+	// CanceledCalls counts calls that failed after the caller's context was done
+	// and were therefore not recorded, since the breaker was created.
+	CanceledCalls int64
+	Settings      Settings
+}
+
+// FailureRate returns the percentage of counted calls that failed.
+func (s Snapshot) FailureRate() float64 {
+	return percentage(s.Failures, s.Failures+s.Successes)
+}
+
+// SlowCallRate returns the percentage of counted calls that were slow.
+func (s Snapshot) SlowCallRate() float64 {
+	return percentage(s.SlowCalls, s.Failures+s.Successes)
+}
+
+// RetryAfter returns how long an open breaker will keep rejecting calls,
+// measured from now. It returns zero unless the snapshot state is Open.
+func (s Snapshot) RetryAfter(now time.Time) time.Duration {
+	if s.State != Open || !now.Before(s.HalfOpenAt) {
+		return 0
+	}
+	return s.HalfOpenAt.Sub(now)
+}
+
+func (c config) settings() Settings {
+	return Settings{
+		Name:                  c.name,
+		FailureThreshold:      c.failureThreshold,
+		SuccessToClose:        c.successToClose,
+		MaximumProbes:         c.maximumProbes,
+		CooldownTimer:         time.Duration(c.cooldownTimer),
+		WindowSize:            time.Duration(c.windowSize),
+		WindowBuckets:         c.windowBuckets,
+		ResetTimer:            time.Duration(c.resetTimer),
+		SlidingCountWindow:    c.countWindow,
+		FailureRateThreshold:  c.failureRate,
+		MinimumCalls:          c.minimumCalls,
+		SlowCallThreshold:     time.Duration(c.slowCallThreshold),
+		SlowCallRateThreshold: c.slowCallRate,
+		CallTimeout:           time.Duration(c.callTimeout),
+	}
+}
+
+// Name returns the name set with WithName, or "" if the breaker is unnamed.
+func (cb *circuitBreaker) Name() string {
+	return cb.config.name
+}
+
+// State returns the current state of the circuit breaker.
+//
+// An open breaker whose cooldown has elapsed still reports Open until the next
+// call moves it to HalfOpen.
+func (cb *circuitBreaker) State() State {
+	return State(cb.state.Load())
+}
+
+// Snapshot returns the current state, counters and configuration of the circuit breaker.
+// The values are read while holding the transition lock, so they never mix two states.
+func (cb *circuitBreaker) Snapshot() Snapshot {
+	cb.transitionMu.Lock()
+	defer cb.transitionMu.Unlock()
+
+	s := Snapshot{
+		State:            State(cb.state.Load()),
+		ConsecutiveOpens: cb.consecutiveOpens,
+		Cooldown:         time.Duration(cb.cooldown),
+		ProbesInUse:      len(cb.probeSem),
+		DroppedEvents:    cb.events.dropped.Load(),
+		Fallbacks:        cb.fallbacks.Load(),
+		FallbackFailures: cb.fallbackFailures.Load(),
+		TimedOutCalls:    cb.timedOutCalls.Load(),
+		CanceledCalls:    cb.canceledCalls.Load(),
+		Settings:         cb.config.settings(),
+	}
+	if s.State == Closed || s.State == Disabled {
+		counts := cb.window.counts()
+		s.Failures, s.Successes, s.SlowCalls = counts.failures, counts.successes(), counts.slow
+	} else {
+		s.Failures, s.Successes, s.SlowCalls = cb.failureCount.Load(), cb.successCount.Load(), cb.slowCount.Load()
+	}
+	if s.State == Open {
+		s.HalfOpenAt = time.Unix(0, cb.halfOpenWhen.Load())
+	}
+	return s
+}
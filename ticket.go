@@ -0,0 +1,95 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Ticket is an admitted call whose outcome is reported later, for work that does not
+// fit in a single callback such as streams or replies arriving on another goroutine.
+// Exactly one outcome is recorded: the first call to any method wins and the rest are
+// no-ops, so a deferred Ignore is a safe way to never leak a half-open probe slot.
+// Methods may be called from any goroutine.
+type Ticket interface {
+	// Success records the call as successful, or as slow if it exceeded the slow
+	// call threshold since Allow.
+	Success()
+	// Failure records the call as failed with err as the cause.
+	Failure(err error)
+	// Ignore releases the call without counting it either way.
+	Ignore()
+}
+
+// Allow admits a call without running it and returns a Ticket on which the outcome
+// must be reported. A rejected call returns an *OpenError matching ErrCircuitOpen,
+// and a done ctx returns ctx.Err(), both without a ticket.
+//
+// Unlike Try, no call timeout is applied and errors are not classified: the caller
+// decides which outcome to report.
+func (cb *circuitBreaker) Allow(ctx context.Context) (Ticket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ar := cb.allow()
+	if !ar.allowed {
+		err := cb.openError(ar)
+		cb.reject(ar)
+		return nil, err
+	}
+	return cb.newTicket(ar), nil
+}
+
+type ticket struct {
+	cb    *circuitBreaker
+	ar    allowResult
+	start time.Time
+	done  atomic.Bool
+}
+
+// newTicket starts tracking an admitted call.
+func (cb *circuitBreaker) newTicket(ar allowResult) *ticket {
+	t := &ticket{cb: cb, ar: ar}
+	if ar.hasProbe && cb.events.enabled() {
+		cb.publish(EventProbeStart, ar.state, ar.state, nil)
+	}
+	if cb.config.slowCallThreshold > 0 {
+		t.start = cb.clock.Now()
+	}
+	return t
+}
+
+func (t *ticket) Success() {
+	t.finish(outcomeSuccess, nil)
+}
+
+func (t *ticket) Failure(err error) {
+	t.finish(outcomeFailure, err)
+}
+
+func (t *ticket) Ignore() {
+	t.finish(outcomeIgnored, nil)
+}
+
+// finish records the outcome of the call and releases its probe slot, once.
+// err is the call's error, reported with the probe-finish event.
+func (t *ticket) finish(o outcome, err error) {
+	if !t.done.CompareAndSwap(false, true) {
+		return
+	}
+
+	cb := t.cb
+	switch o {
+	case outcomeFailure:
+		cb.complete(t.start, newSample(true), err)
+	case outcomeSuccess:
+		cb.complete(t.start, newSample(false), nil)
+	}
+
+	if t.ar.hasProbe {
+		cb.releaseProbe()
+		if cb.events.enabled() {
+			cb.publish(EventProbeFinish, t.ar.state, State(cb.state.Load()), err)
+		}
+	}
+}
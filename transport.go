@@ -0,0 +1,173 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Transport is an http.RoundTripper that guards each host with its own circuit breaker,
+// so an *http.Client handed to a third-party SDK still fails fast when a dependency is down.
+//
+// Responses are classified like ExecuteHTTPBlocking: by default transport errors and 408,
+// 429 and 5xx responses count as failures, everything else as success, and breakers
+// created with WithHTTPClassifier use that classifier instead. Responses are always
+// returned to the caller unchanged; a rejected request returns an *OpenError without
+// touching the network. Transport does not retry. Breakers created with
+// WithRetryAfterCooldown stay open for as long as a 429 or 503 response's Retry-After asks,
+// and a WithCallTimeout bounds the wait for the response headers.
+//
+// When base is an *http.Transport, each key sends its requests through its own clone of
+// it, so connection limits apply per key and a breaker opening closes the idle
+// connections of its key only: probes after the cooldown dial fresh connections while
+// other hosts keep theirs. Other round trippers are shared by every key and their
+// connections are left alone.
+type Transport struct {
+	base     http.RoundTripper
+	key      func(*http.Request) string
+	breakers *Registry
+
+	mu    sync.Mutex
+	pools map[string]*http.Transport // per-key clones of base, nil unless it is an *http.Transport
+}
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil. Breakers are keyed
+// by host unless WithKeyFunc is given, and created with the options from WithBreakerOptions.
+func NewTransport(base http.RoundTripper, opts ...HTTPOption) (*Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c, err := newHTTPConfig(hostKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.registry != nil {
+		return nil, fmt.Errorf("transport creates its own breakers, use WithBreakerOptions instead of WithRegistry")
+	}
+
+	t := &Transport{base: base, key: c.key}
+	t.breakers, err = NewRegistry(slices.Clone(c.breakerOpts)...)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := base.(*http.Transport); ok {
+		t.pools = make(map[string]*http.Transport)
+		t.breakers.named = func(key string) []Option {
+			return []Option{WithOnStateChange(t.closeIdleOnOpen(key))}
+		}
+	}
+	return t, nil
+}
+
+// RoundTrip sends req through the breaker for its key.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.key(req)
+	cb, err := t.breakers.Get(key)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	// The registry only holds breakers built by New.
+	b := cb.(*circuitBreaker)
+
+	var resp *http.Response
+	var o HTTPOutcome
+	err = b.Try(req.Context(), func(ctx context.Context) error {
+		var err error
+		resp, err = b.config.sendWithin(ctx, req, t.roundTripper(key).RoundTrip)
+		o = b.config.classifyHTTP(resp, err)
+		switch o {
+		case HTTPSuccess:
+			return &decided{o: outcomeSuccess, err: err}
+		case HTTPRetryable:
+			return httpError(resp, err, o)
+		case HTTPNonRetryable:
+			return &decided{o: outcomeFailure, err: httpError(resp, err, o)}
+		default:
+			return &decided{o: outcomeIgnored, err: err}
+		}
+	})
+	if resp != nil {
+		// Like ExecuteHTTPBlocking, only a response classified as retryable can hold
+		// the circuit open.
+		if o == HTTPRetryable && b.config.retryAfterCooldown {
+			if d := retryAfter(resp, b.clock.Now()); d > 0 {
+				b.openFor(d, err)
+			}
+		}
+		return resp, nil
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		// RoundTrippers must close the body even when the request is never sent.
+		closeRequestBody(req)
+	}
+	return nil, err
+}
+
+// Breakers returns the registry holding the per-key breakers, for overrides and metrics.
+func (t *Transport) Breakers() *Registry {
+	return t.breakers
+}
+
+// CloseIdleConnections closes idle connections of the base transport, if it supports it,
+// and of every key's clone of it.
+func (t *Transport) CloseIdleConnections() {
+	if ci, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		ci.CloseIdleConnections()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pool := range t.pools {
+		pool.CloseIdleConnections()
+	}
+}
+
+// Close closes every breaker created by the transport and the idle connections of
+// its per-key clones, which nothing else would close.
+func (t *Transport) Close() {
+	t.breakers.Close()
+	t.CloseIdleConnections()
+}
+
+// roundTripper returns the transport for key's requests: its own clone of base when
+// base is an *http.Transport, created on first use, and base itself otherwise.
+func (t *Transport) roundTripper(key string) http.RoundTripper {
+	if t.pools == nil {
+		return t.base
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pool, ok := t.pools[key]
+	if !ok {
+		pool = t.base.(*http.Transport).Clone()
+		t.pools[key] = pool
+	}
+	return pool
+}
+
+// closeIdleOnOpen returns a listener that drops key's pooled connections when its
+// breaker opens, so probes after the cooldown dial fresh connections instead of
+// reusing ones to a failed backend.
+func (t *Transport) closeIdleOnOpen(key string) StateChangeFunc {
+	return func(_, to State, _ error) {
+		if to != Open && to != ForcedOpen {
+			return
+		}
+		t.mu.Lock()
+		pool := t.pools[key]
+		t.mu.Unlock()
+		if pool != nil {
+			pool.CloseIdleConnections()
+		}
+	}
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,163 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// sample is the outcome of a single call as stored in a window.
+// The zero value marks an empty slot.
+type sample uint32
+
+const (
+	sampleCall sample = 1 << iota
+	sampleFailure
+	sampleSlow
+)
+
+func newSample(failed bool) sample {
+	s := sampleCall
+	if failed {
+		s |= sampleFailure
+	}
+	return s
+}
+
+// bit returns 1 if flag is set in s, 0 otherwise.
+func (s sample) bit(flag sample) int64 {
+	if s&flag != 0 {
+		return 1
+	}
+	return 0
+}
+
+// windowCounts aggregates the samples currently held by a window.
+type windowCounts struct {
+	calls    int64
+	failures int64
+	slow     int64
+}
+
+func (w windowCounts) successes() int64 {
+	return w.calls - w.failures
+}
+
+// window records the outcomes of calls made while the breaker is closed.
+type window interface {
+	record(s sample)
+	counts() windowCounts
+	reset()
+}
+
+// timeWindow counts the outcomes of calls made during the last windowSize,
+// split into buckets that age out one at a time as the clock advances.
+//
+// Counts cover between windowSize-width and windowSize of traffic, depending
+// on how far the clock is into the current bucket.
+type timeWindow struct {
+	clock Clock
+	width int64
+
+	mu      sync.Mutex
+	buckets []timeBucket
+}
+
+type timeBucket struct {
+	epoch    int64
+	calls    int64
+	failures int64
+	slow     int64
+}
+
+func newTimeWindow(clock Clock, windowSize, buckets int64) *timeWindow {
+	return &timeWindow{
+		clock:   clock,
+		width:   windowSize / buckets,
+		buckets: make([]timeBucket, buckets),
+	}
+}
+
+// epoch returns the index of the bucket period containing now.
+func (w *timeWindow) epoch() int64 {
+	return w.clock.Now().UnixNano() / w.width
+}
+
+func (w *timeWindow) record(s sample) {
+	epoch := w.epoch()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[uint64(epoch)%uint64(len(w.buckets))]
+	if b.epoch != epoch {
+		*b = timeBucket{epoch: epoch}
+	}
+	b.calls += s.bit(sampleCall)
+	b.failures += s.bit(sampleFailure)
+	b.slow += s.bit(sampleSlow)
+}
+
+func (w *timeWindow) counts() windowCounts {
+	oldest := w.epoch() - int64(len(w.buckets)) + 1
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var c windowCounts
+	for _, b := range w.buckets {
+		if b.epoch >= oldest {
+			c.calls += b.calls
+			c.failures += b.failures
+			c.slow += b.slow
+		}
+	}
+	return c
+}
+
+func (w *timeWindow) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	clear(w.buckets)
+}
+
+// countWindow keeps the outcomes of the last len(slots) calls in a ring buffer.
+//
+// Recording is lock-free: each call claims the next slot with an atomic
+// increment and swaps its sample in, then adjusts the running totals by the
+// difference between the new sample and the one it evicted.
+type countWindow struct {
+	slots    []atomic.Uint32
+	next     atomic.Uint64
+	calls    atomic.Int64
+	failures atomic.Int64
+	slow     atomic.Int64
+}
+
+func newCountWindow(size int64) *countWindow {
+	return &countWindow{slots: make([]atomic.Uint32, size)}
+}
+
+func (w *countWindow) record(s sample) {
+	i := (w.next.Add(1) - 1) % uint64(len(w.slots))
+	w.apply(s, sample(w.slots[i].Swap(uint32(s))))
+}
+
+func (w *countWindow) apply(added, evicted sample) {
+	if d := added.bit(sampleCall) - evicted.bit(sampleCall); d != 0 {
+		w.calls.Add(d)
+	}
+	if d := added.bit(sampleFailure) - evicted.bit(sampleFailure); d != 0 {
+		w.failures.Add(d)
+	}
+	if d := added.bit(sampleSlow) - evicted.bit(sampleSlow); d != 0 {
+		w.slow.Add(d)
+	}
+}
+
+func (w *countWindow) counts() windowCounts {
+	return windowCounts{calls: w.calls.Load(), failures: w.failures.Load(), slow: w.slow.Load()}
+}
+
+func (w *countWindow) reset() {
+	for i := range w.slots {
+		w.apply(0, sample(w.slots[i].Swap(0)))
+	}
+}